@@ -0,0 +1,217 @@
+package chart
+
+/*
+	Minimal SVG renderer for model reports. It draws histograms,
+	line charts and heatmaps without any external tools.
+*/
+
+import (
+	"bufio"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+)
+
+const (
+	width    = 640
+	height   = 400
+	padLeft  = 60
+	padTop   = 40
+	padBot   = 50
+	padRight = 20
+	ticks    = 5
+)
+
+type (
+	canvas struct {
+		w *bufio.Writer
+	}
+)
+
+var (
+	ErrEmptyData = errors.New("no data to draw")
+)
+
+func newCanvas(w io.Writer, title string) *canvas {
+	c := &canvas{w: bufio.NewWriter(w)}
+	fmt.Fprintf(c.w, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="sans-serif" font-size="12">`+"\n",
+		width, height, width, height)
+	fmt.Fprintf(c.w, `<rect width="%d" height="%d" fill="white"/>`+"\n", width, height)
+	c.text(width/2, padTop/2+5, "middle", 14, title)
+	return c
+}
+
+func (c *canvas) text(x, y float64, anchor string, size int, s string) {
+	fmt.Fprintf(c.w, `<text x="%.1f" y="%.1f" text-anchor="%s" font-size="%d">%s</text>`+"\n",
+		x, y, anchor, size, escape(s))
+}
+
+func (c *canvas) line(x1, y1, x2, y2 float64, color string) {
+	fmt.Fprintf(c.w, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="%s"/>`+"\n",
+		x1, y1, x2, y2, color)
+}
+
+func (c *canvas) rect(x, y, w, h float64, fill, title string) {
+	fmt.Fprintf(c.w, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="%s"><title>%s</title></rect>`+"\n",
+		x, y, w, h, fill, escape(title))
+}
+
+// axes draws plot frame with y ticks from 0 to maxY.
+func (c *canvas) axes(xlabel, ylabel string, maxY float64) {
+	x0, y0 := float64(padLeft), float64(height-padBot)
+	c.line(x0, y0, width-padRight, y0, "black")
+	c.line(x0, y0, x0, padTop, "black")
+	for i := 0; i <= ticks; i++ {
+		v := maxY * float64(i) / ticks
+		y := y0 - (y0-padTop)*float64(i)/ticks
+		c.line(x0-4, y, x0, y, "black")
+		c.text(x0-6, y+4, "end", 10, formatTick(v))
+	}
+	c.text((x0+width-padRight)/2, height-10, "middle", 12, xlabel)
+	fmt.Fprintf(c.w, `<text x="15" y="%.1f" text-anchor="middle" transform="rotate(-90 15 %.1f)">%s</text>`+"\n",
+		(y0+padTop)/2, (y0+padTop)/2, escape(ylabel))
+}
+
+func (c *canvas) close() error {
+	fmt.Fprintln(c.w, "</svg>")
+	return c.w.Flush()
+}
+
+// Histogram draws bar chart where labels[i] is a caption of values[i].
+func Histogram(w io.Writer, title, xlabel, ylabel string, labels []string, values []float64) error {
+	if len(values) == 0 || len(labels) != len(values) {
+		return ErrEmptyData
+	}
+	maxY := niceMax(values)
+	c := newCanvas(w, title)
+	c.axes(xlabel, ylabel, maxY)
+
+	plotW := float64(width - padLeft - padRight)
+	plotH := float64(height - padTop - padBot)
+	step := plotW / float64(len(values))
+	for i, v := range values {
+		h := plotH * v / maxY
+		x := padLeft + step*float64(i)
+		c.rect(x+step*0.1, float64(height-padBot)-h, step*0.8, h, "steelblue",
+			fmt.Sprintf("%s: %s", labels[i], formatTick(v)))
+		c.text(x+step/2, height-padBot+15, "middle", 10, labels[i])
+	}
+	return c.close()
+}
+
+// Line draws curve of ys values, xs[i] is a caption of ys[i].
+func Line(w io.Writer, title, xlabel, ylabel string, xs []string, ys []float64) error {
+	if len(ys) == 0 || len(xs) != len(ys) {
+		return ErrEmptyData
+	}
+	maxY := niceMax(ys)
+	c := newCanvas(w, title)
+	c.axes(xlabel, ylabel, maxY)
+
+	plotW := float64(width - padLeft - padRight)
+	plotH := float64(height - padTop - padBot)
+	step := plotW
+	if len(ys) > 1 {
+		step = plotW / float64(len(ys)-1)
+	}
+	points := make([]string, 0, len(ys))
+	labelEvery := len(ys)/10 + 1
+	for i, v := range ys {
+		x := padLeft + step*float64(i)
+		y := float64(height-padBot) - plotH*v/maxY
+		points = append(points, fmt.Sprintf("%.1f,%.1f", x, y))
+		fmt.Fprintf(c.w, `<circle cx="%.1f" cy="%.1f" r="3" fill="steelblue"><title>%s: %s</title></circle>`+"\n",
+			x, y, escape(xs[i]), formatTick(v))
+		if i%labelEvery == 0 {
+			c.text(x, height-padBot+15, "middle", 10, xs[i])
+		}
+	}
+	fmt.Fprintf(c.w, `<polyline points="%s" fill="none" stroke="steelblue" stroke-width="2"/>`+"\n",
+		strings.Join(points, " "))
+	return c.close()
+}
+
+// Heatmap draws values[row][col] grid, where rows are captioned
+// with ylabels and columns with xlabels.
+func Heatmap(w io.Writer, title, xlabel, ylabel string, xlabels, ylabels []string, values [][]float64) error {
+	if len(values) == 0 || len(values) != len(ylabels) {
+		return ErrEmptyData
+	}
+	minV, maxV := math.Inf(1), math.Inf(-1)
+	for _, row := range values {
+		if len(row) != len(xlabels) {
+			return ErrEmptyData
+		}
+		for _, v := range row {
+			minV = math.Min(minV, v)
+			maxV = math.Max(maxV, v)
+		}
+	}
+	c := newCanvas(w, title)
+	x0, y0 := float64(padLeft), float64(height-padBot)
+	cellW := float64(width-padLeft-padRight) / float64(len(xlabels))
+	cellH := float64(height-padTop-padBot) / float64(len(ylabels))
+
+	for i, row := range values {
+		y := y0 - cellH*float64(i+1)
+		c.text(x0-6, y+cellH/2+4, "end", 10, ylabels[i])
+		for j, v := range row {
+			x := x0 + cellW*float64(j)
+			c.rect(x, y, cellW, cellH, heatColor(v, minV, maxV),
+				fmt.Sprintf("%s=%s %s=%s: %s", ylabel, ylabels[i], xlabel, xlabels[j], formatTick(v)))
+			if cellW > 30 && cellH > 14 {
+				c.text(x+cellW/2, y+cellH/2+4, "middle", 10, formatTick(v))
+			}
+		}
+	}
+	for j, l := range xlabels {
+		c.text(x0+cellW*(float64(j)+0.5), y0+15, "middle", 10, l)
+	}
+	c.text((x0+width-padRight)/2, height-10, "middle", 12, xlabel)
+	fmt.Fprintf(c.w, `<text x="15" y="%.1f" text-anchor="middle" transform="rotate(-90 15 %.1f)">%s</text>`+"\n",
+		(y0+padTop)/2, (y0+padTop)/2, escape(ylabel))
+	return c.close()
+}
+
+// heatColor maps v into white-to-red gradient.
+func heatColor(v, min, max float64) string {
+	t := 0.0
+	if max > min {
+		t = (v - min) / (max - min)
+	}
+	g := int(255 * (1 - t))
+	return fmt.Sprintf("rgb(255,%d,%d)", g, g)
+}
+
+func niceMax(values []float64) float64 {
+	max := 0.0
+	for _, v := range values {
+		max = math.Max(max, v)
+	}
+	if max <= 0 {
+		return 1
+	}
+	exp := math.Pow(10, math.Floor(math.Log10(max)))
+	for _, m := range []float64{1, 2, 5, 10} {
+		if m*exp >= max {
+			return m * exp
+		}
+	}
+	return 10 * exp
+}
+
+func formatTick(v float64) string {
+	if v == math.Trunc(v) {
+		return fmt.Sprintf("%d", int64(v))
+	}
+	return fmt.Sprintf("%.2f", v)
+}
+
+func escape(s string) string {
+	b := new(strings.Builder)
+	xml.EscapeText(b, []byte(s))
+	return b.String()
+}
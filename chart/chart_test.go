@@ -0,0 +1,48 @@
+package chart
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func requireValidSVG(t *testing.T, data []byte) {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		_, err := dec.Token()
+		if err == io.EOF {
+			return
+		}
+		require.NoError(t, err)
+	}
+}
+
+func TestHistogram(t *testing.T) {
+	buf := new(bytes.Buffer)
+	require.NoError(t, Histogram(buf, "hops <test>", "x", "y", []string{"1", "2", "inf"}, []float64{10, 85.5, 4.5}))
+	requireValidSVG(t, buf.Bytes())
+	require.Contains(t, buf.String(), "hops &lt;test&gt;")
+
+	require.Equal(t, ErrEmptyData, Histogram(buf, "", "", "", []string{"1"}, nil))
+}
+
+func TestLine(t *testing.T) {
+	buf := new(bytes.Buffer)
+	require.NoError(t, Line(buf, "coverage", "x", "y", []string{"0", "1", "2"}, []float64{1, 10, 100}))
+	requireValidSVG(t, buf.Bytes())
+	require.Contains(t, buf.String(), "<polyline")
+}
+
+func TestHeatmap(t *testing.T) {
+	buf := new(bytes.Buffer)
+	values := [][]float64{{1, 2}, {3, 4}}
+	require.NoError(t, Heatmap(buf, "sweep", "fanout", "size", []string{"2", "3"}, []string{"10", "20"}, values))
+	requireValidSVG(t, buf.Bytes())
+	require.Contains(t, buf.String(), "rgb(255,255,255)")
+	require.Contains(t, buf.String(), "rgb(255,0,0)")
+
+	require.Equal(t, ErrEmptyData, Heatmap(buf, "", "", "", []string{"2"}, []string{"10"}, [][]float64{{1, 2}}))
+}
@@ -1,126 +1,133 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"gossipmodel/model"
+	"gossipmodel/report"
+	"gossipmodel/runner"
+	"gossipmodel/server"
 	"math/rand"
+	"net/http"
 	"os"
-	"runtime"
-	"sort"
+	"os/signal"
 	"strconv"
-	"sync"
+	"strings"
 	"time"
-
-	"github.com/abiosoft/ishell"
 )
 
 var (
 	R *rand.Rand
 )
 
-func jobWorker(job chan struct{}, c *model.EpochCounter, wg *sync.WaitGroup, s int, f int, iid int, d bool) {
-	defer func() {
-		wg.Done()
-	}()
-	for range job {
-		// Experimental routine starts here
-		netmap, err := model.SampleNetwork(s)
-		if err != nil {
-			panic(err)
-		}
-		err = netmap.VisitNode(iid)
-		if err != nil {
-			panic(err)
-		}
-
-		i := -1
-		reused := 0
-
-		for !netmap.IsNetworkFilled() {
-			i++
-			if i > len(netmap.Topology) {
-				// debug only
-				if d {
-					fmt.Println("Found infinite cycle!")
-					for epochNum := 0; epochNum < len(netmap.Topology); epochNum++ {
-						if epoch, ok := netmap.History[epochNum]; ok {
-							fmt.Println("Epoch:", epochNum+1)
-							for nodeid, data := range epoch {
-								fmt.Printf("  Node:#%d %v\n", nodeid, data)
-							}
-						}
-					}
-					os.Exit(1)
-				}
-				c.InfCounter++
-				break
-			}
-			// Here we calling gossip algorithm
-			stat := netmap.RunEpochNaiveOnce(f, i)
-			reused += stat.Reused
-		}
-		if netmap.IsNetworkFilled() {
-			c.Inc(i)
-			c.AddRe(reused)
-		}
+// exportDOT runs single experiment and writes its propagation graph
+// into the file in Graphviz DOT format.
+func exportDOT(path string, cfg runner.Config) error {
+	cfg.Experiments = 1
+	if err := cfg.Validate(); err != nil {
+		return err
 	}
+	e := runner.Single(cfg)
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return e.Network.WriteDOT(f)
 }
 
-func runExperiment(size int, fanout int, numexp int, initid int, debug bool) {
-	start := time.Now()
-	defer func() {
-		fmt.Println(time.Since(start))
+// serve runs HTTP API server until ctx is done.
+func serve(ctx context.Context, addr string) error {
+	srv := &http.Server{
+		Addr:    addr,
+		Handler: server.New(ctx),
+	}
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- srv.ListenAndServe()
 	}()
+	fmt.Printf("Serving HTTP API and web UI on http://%s/\n", addr)
 
-	workerCount := runtime.NumCPU() + runtime.NumCPU()/2
-
-	wg := new(sync.WaitGroup)
-	wg.Add(workerCount)
-
-	c := model.EpochCounter{
-		Mu:         new(sync.Mutex),
-		Counter:    make(map[int]int),
-		ReCounter:  0,
-		InfCounter: 0,
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
 	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return srv.Shutdown(shutdownCtx)
+}
 
-	jobs := make(chan struct{}, numexp)
-
-	for j := 0; j < numexp; j++ {
-		jobs <- struct{}{}
+// runExperiment executes experiments of the config and prints aggregated
+// results. When ctx is done before all experiments are finished, results
+// contain only completed experiments. With progress flag it draws live
+// dashboard, in debug mode the run stops at the first infinite cycle.
+func runExperiment(ctx context.Context, cfg runner.Config, debug bool, progress bool) (runner.Result, error) {
+	cfg.StopOnInfinite = debug
+	cfg.Record = debug
+	r, err := runner.New(cfg)
+	if err != nil {
+		return runner.Result{}, err
 	}
 
-	for j := 0; j < workerCount; j++ {
-		go jobWorker(jobs, &c, wg, size, fanout, initid, debug)
+	done := make(chan struct{})
+	watched := make(chan struct{})
+	if progress {
+		go func() {
+			report.NewDashboard(os.Stderr).Watch(r, done)
+			close(watched)
+		}()
+	} else {
+		close(watched)
 	}
-	close(jobs)
-	wg.Wait()
+
+	res := r.Run(ctx)
+	close(done)
+	<-watched
 
 	if debug {
-		dataString := ""
-		for i := 0; i < 20; i++ {
-			if try, ok := c.Counter[i]; ok {
-				dataString += fmt.Sprintf("%d;", try)
-			} else {
-				dataString += "0;"
-			}
+		if res.InfiniteExperiment != nil {
+			report.Replay(os.Stdout, res.InfiniteExperiment.Frames)
 		}
-		fmt.Printf("%d;%d;%s\n", size, fanout, dataString)
+		report.CSV(os.Stdout, res)
+		// keep CSV on stdout clean
+		fmt.Fprintln(os.Stderr, res.Elapsed)
 	} else {
-		fmt.Printf("Size: %d Fan-out: %d\n", size, fanout)
-		hopNumbers := make([]int, 0, len(c.Counter))
-		for hop := range c.Counter {
-			hopNumbers = append(hopNumbers, hop)
-		}
-		sort.Ints(hopNumbers) //sort by key
-		for _, ind := range hopNumbers {
-			fmt.Printf("%d:%d (%.2f%%)  ", ind+1, c.Counter[ind],
-				float32(c.Counter[ind])/float32(numexp)*100)
-		}
-		fmt.Printf("inf:%d (%.2f%%)\n", c.InfCounter, float32(c.InfCounter)/float32(numexp)*100)
-		fmt.Printf("Reused avg: %d\n", c.ReCounter/numexp)
+		report.Text(os.Stdout, res)
+	}
+	return res, nil
+}
+
+// parseLevels parses comma separated coverage levels in percent.
+func parseLevels(s string) ([]float64, error) {
+	var levels []float64
+	for _, f := range strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ' ' }) {
+		v, err := strconv.ParseFloat(f, 64)
+		if err != nil || v <= 0 || v > 100 {
+			return nil, fmt.Errorf("incorrect coverage level %q", f)
+		}
+		levels = append(levels, v)
 	}
+	return levels, nil
+}
+
+// interruptContext returns context which is canceled on the first
+// interrupt signal, the second one kills the process.
+func interruptContext() (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt)
+	go func() {
+		select {
+		case <-sig:
+			cancel()
+		case <-ctx.Done():
+		}
+		signal.Stop(sig)
+	}()
+	return ctx, cancel
 }
 
 func main() {
@@ -130,47 +137,194 @@ func main() {
 	numExperiments := flag.Int("c", 10, "number of experiments")
 	debug := flag.Bool("debug", false, "debug mode")
 	repl := flag.Bool("i", false, "interactive mode")
+	dotPath := flag.String("dot", "", "export propagation graph of single experiment into DOT file")
+	svgDir := flag.String("svg", "", "directory to render result charts in SVG")
+	progress := flag.Bool("progress", false, "show live progress")
+	timeout := flag.Duration("timeout", 0, "time budget of the run, partial results are printed when exceeded")
+	maxExperiments := flag.Int("max-experiments", 0, "upper bound of experiments in the run")
+	serveAddr := flag.String("serve", "", "serve HTTP API and web UI on the address, e.g. localhost:8080")
+	compare := flag.String("compare", "", "comma separated algorithms or param=value variants to compare on identical random streams")
+	seed := flag.Int64("seed", 0, "seed of random streams, zero for crypto random source")
+	antithetic := flag.Bool("antithetic", false, "run experiments in antithetic pairs")
+	maxEpochs := flag.Int("max-epochs", 0, "epoch limit of single experiment, zero for network size")
+	slo := flag.String("slo", "", "comma separated coverage levels in percent to report reliability, e.g. 99,99.9")
+	load := flag.Bool("load", false, "report per-node load and fairness")
+	latency := flag.Bool("latency", false, "report hops when nodes got data")
+	views := flag.Bool("views", false, "report in-degree and clustering of membership views")
+	probability := flag.Float64("p", 0, "forwarding probability of gossip1, gossip2 and gossip-edge algorithms, zero for 1")
+	forwardHops := flag.Int("forward-hops", 1, "number of the first hops which always forward in gossip1, gossip2 and gossip-edge algorithms")
+	lowProbability := flag.Float64("low-p", 0, "forwarding probability of gossip2 nodes with less than low-degree neighbors, zero for 1")
+	lowDegree := flag.Int("low-degree", 0, "gossip2 nodes with less neighbors forward with low-p probability")
+	ttl := flag.Int("ttl", 0, "limit of hops data travels, zero for no limit")
+	percolation := flag.Int("percolation", 0, "scan forwarding probabilities in the number of steps and find percolation threshold")
+	plumtree := flag.Int("plumtree", 0, "run the number of broadcasts over Plumtree and compare messages with naive-once")
+	crash := flag.Int("crash", 0, "number of nodes crashed before propagation")
+	plumtreeCrash := flag.Int("plumtree-crash", 0, "number of nodes crashed in the middle of Plumtree broadcasts")
+	swimCrash := flag.Int("swim-crash", 0, "number of nodes crashed before SWIM periods")
+	viewSize := flag.Int("view-size", 0, "size of partial views, zero for fan-out+1")
+	membership := flag.String("membership", model.GlobalMembership, "membership senders choose nodes from: "+strings.Join(model.MembershipNames(), ", "))
+	graftTimeout := flag.Int("graft-timeout", model.DefaultGraftTimeout, "epochs Plumtree node waits for data after IHAVE before GRAFT")
+	swim := flag.Int("swim", 0, "run the number of protocol periods of SWIM failure detector")
+	probes := flag.Int("probes", model.DefaultIndirectProbes, "number of SWIM PING-REQ without ack")
+	suspicion := flag.Int("suspicion", 0, "periods before SWIM suspect is confirmed dead, zero for 4*ceil(log10(size))")
+	loss := flag.String("loss", model.NoLoss, "message loss model of SWIM: "+strings.Join(model.LossNames(), ", "))
+	lossRate := flag.Float64("loss-rate", 0, "share of lost messages")
+	scuttlebutt := flag.Int("scuttlebutt", 0, "run the number of epochs of scuttlebutt, anti-entropy and push state reconciliation")
+	keys := flag.Int("keys", model.DefaultKeys, "keys of every node of state reconciliation")
+	updateRate := flag.Float64("update-rate", 0.5, "probability node updates a key in epoch of state reconciliation")
+	mtu := flag.Int("mtu", model.DefaultMTU, "deltas per exchange of scuttlebutt and push")
+	rare := flag.Bool("rare", false, "estimate probability that network is not filled with importance sampling")
+	algorithm := flag.String("alg", model.DefaultAlgorithm, "propagation algorithm: "+strings.Join(model.AlgorithmNames(), ", "))
 	flag.Parse()
 
+	cfg := runner.Config{
+		Size:           *sampleSize,
+		Fanout:         *fanoutSize,
+		Experiments:    *numExperiments,
+		InitialNode:    *initialNode,
+		Algorithm:      *algorithm,
+		Seed:           *seed,
+		Antithetic:     *antithetic,
+		MaxEpochs:      *maxEpochs,
+		Probability:    *probability,
+		ForwardHops:    *forwardHops,
+		LowProbability: *lowProbability,
+		LowDegree:      *lowDegree,
+		TTL:            *ttl,
+		Membership:     *membership,
+		ViewSize:       *viewSize,
+		Crash:          *crash,
+	}
+
+	if *dotPath != "" {
+		if err := exportDOT(*dotPath, cfg); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *serveAddr != "" {
+		ctx, cancel := interruptContext()
+		defer cancel()
+		if err := serve(ctx, *serveAddr); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	if *repl {
 		fmt.Println("Interactive push-gossip model runner")
-		fmt.Println("Print 'run' and fill model parameters")
-		shell := ishell.New()
-		shell.AddCmd(&ishell.Cmd{
-			Name: "run",
-			Help: "run gossip experiment",
-			Func: func(c *ishell.Context) {
-				// disable the '>>>' for cleaner same line input.
-				c.ShowPrompt(false)
-				defer c.ShowPrompt(true) // yes, revert after login.
-
-				c.Print("Network size: ")
-				netsize, err := strconv.Atoi(c.ReadLine())
-				if err != nil || netsize <= 0 {
-					c.Println("Incorrect network size")
-					return
-				}
-
-				c.Print("Fan-out size: ")
-				fanout, err := strconv.Atoi(c.ReadLine())
-				if err != nil || fanout <= 0 || fanout > netsize-1 {
-					c.Println("Incorrect fan-out size")
-					return
-				}
-
-				c.Print("Number of experiments: ")
-				expnum, err := strconv.Atoi(c.ReadLine())
-				if err != nil || netsize <= 0 {
-					c.Println("Incorrect number of experiments")
-					return
-				}
-
-				c.Println("-----------")
-				runExperiment(netsize, fanout, expnum, 0, false)
-			},
-		})
-		shell.Run()
+		fmt.Println("Print 'set' and 'show' to change model parameters, 'run' to start experiments, 'help' for other commands")
+		newShell(cfg).Run()
 	} else {
-		runExperiment(*sampleSize, *fanoutSize, *numExperiments, *initialNode, *debug)
+		ctx, cancel := interruptContext()
+		defer cancel()
+		if *timeout > 0 {
+			ctx, cancel = context.WithTimeout(ctx, *timeout)
+			defer cancel()
+		}
+
+		if *maxExperiments > 0 && *maxExperiments < cfg.Experiments {
+			cfg.Experiments = *maxExperiments
+		}
+		if *compare != "" {
+			cmp, err := runner.Compare(ctx, cfg, strings.Split(*compare, ","))
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			report.Comparison(os.Stdout, cmp)
+			return
+		}
+
+		if *percolation > 0 {
+			res, err := runner.PercolationScan(ctx, cfg, *percolation)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			report.Percolation(os.Stdout, res)
+			return
+		}
+		if *plumtree > 0 {
+			res, err := runner.Plumtree(ctx, cfg, runner.PlumtreeConfig{
+				Broadcasts:   *plumtree,
+				Crash:        *plumtreeCrash,
+				GraftTimeout: *graftTimeout,
+			})
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			report.Plumtree(os.Stdout, res)
+			return
+		}
+		if *swim > 0 {
+			res, err := runner.Swim(ctx, cfg, runner.SwimConfig{
+				Periods:          *swim,
+				Crash:            *swimCrash,
+				IndirectProbes:   *probes,
+				SuspicionTimeout: *suspicion,
+				Loss:             *loss,
+				LossRate:         *lossRate,
+			})
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			report.Swim(os.Stdout, res)
+			return
+		}
+		if *scuttlebutt > 0 {
+			res, err := runner.Reconcile(ctx, cfg, runner.ReconcileConfig{
+				Epochs:     *scuttlebutt,
+				Keys:       *keys,
+				UpdateRate: *updateRate,
+				MTU:        *mtu,
+			})
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			report.Reconcile(os.Stdout, res)
+			return
+		}
+		if *rare {
+			res, err := runner.RareEvent(ctx, cfg)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			report.RareEvent(os.Stdout, res)
+			return
+		}
+
+		levels, err := parseLevels(*slo)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		res, err := runExperiment(ctx, cfg, *debug, *progress)
+		if err == nil && *slo != "" {
+			report.Reliability(os.Stdout, res, levels)
+		}
+		if err == nil && *load {
+			report.Load(os.Stdout, res)
+		}
+		if err == nil && *latency {
+			report.Latency(os.Stdout, res)
+		}
+		if err == nil && *views {
+			report.Views(os.Stdout, res)
+		}
+		if err == nil && *svgDir != "" {
+			err = report.Charts(*svgDir, res)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
 	}
 }
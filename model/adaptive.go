@@ -0,0 +1,151 @@
+package model
+
+import "math"
+
+/*
+	Adaptive variants of RunEpochNaiveOnce: every node propagates data once
+	in lifetime, but fan-out of a sender is chosen by a rule instead of
+	fixed value F passed to algorithm:
+	`- epoch     : F-e for epoch e, so late senders send less
+	`- decay     : F*(3/4)^e, geometric decay over epochs
+	`- log-size  : ln(N)+F for network size N estimated by the node, F is
+	               a constant of classic ln(N)+c fan-out
+	`- duplicate : F-d, where d is a number of duplicates the node received
+	               before it propagated data
+	Fan-out is never less than one.
+
+	Nodes estimate network size by push-pull averaging over their
+	membership (Jelasity et al.): node with data starts with value 1, others
+	with 0, in each of sizeRounds rounds every alive node averages its value
+	with a random peer of its view. Sum of values stays 1 and all values
+	converge to 1/N, so node estimates N as inverse of its value. Estimate
+	is as good as mixing of views: nodes never reached by averaging know
+	nothing and estimate 1.
+*/
+
+const (
+	decayRate  = 0.75 // factor of fan-out decay per epoch of RunEpochDecayOnce
+	sizeRounds = 30   // averaging rounds of network size estimation
+)
+
+// Fan-out decreases by one each epoch.
+// Topology notation is the same as in RunEpochNaiveOnce.
+func (n *Network) RunEpochEpochOnce(fanout int, epoch int) Stat {
+	return n.runEpochOnceAdaptive(epoch, func(int) int {
+		return fanout - epoch
+	})
+}
+
+// Fan-out decays geometrically over epochs.
+// Topology notation is the same as in RunEpochNaiveOnce.
+func (n *Network) RunEpochDecayOnce(fanout int, epoch int) Stat {
+	return n.runEpochOnceAdaptive(epoch, func(int) int {
+		return int(math.Ceil(float64(fanout) * math.Pow(decayRate, float64(epoch))))
+	})
+}
+
+// Fan-out is ln(N)+F for network size N estimated by the sender.
+// Topology notation is the same as in RunEpochNaiveOnce.
+func (n *Network) RunEpochLogSizeOnce(fanout int, epoch int) Stat {
+	return n.runEpochOnceAdaptive(epoch, func(node int) int {
+		return int(math.Ceil(math.Log(float64(n.EstimateSize(node))))) + fanout
+	})
+}
+
+// Fan-out decreases by a number of duplicates the node received before
+// propagation: many duplicates mean that most nodes already have data.
+// Topology notation is the same as in RunEpochNaiveOnce.
+func (n *Network) RunEpochDuplicateOnce(fanout int, epoch int) Stat {
+	return n.runEpochOnceAdaptive(epoch, func(node int) int {
+		duplicates := n.received[node] - 1
+		if duplicates < 0 { // initial node
+			duplicates = 0
+		}
+		return fanout - duplicates
+	})
+}
+
+// EstimateSize returns network size estimated by the node. Estimates of
+// all nodes are made by averaging at the first call.
+func (n *Network) EstimateSize(node int) int {
+	if n.estimates == nil {
+		n.estimates = n.estimateSizes()
+	}
+	return n.estimates[node]
+}
+
+// estimateSizes runs averaging rounds over membership of the network and
+// returns size estimated by every node.
+func (n *Network) estimateSizes() []int {
+	values := make([]float64, len(n.Topology))
+	for node := range values {
+		if n.Topology[node] != 0 {
+			values[node] = 1
+			break
+		}
+	}
+	sampler := n.PeerSampler()
+	for round := 0; round < sizeRounds; round++ {
+		for node := range values {
+			if n.down[node] {
+				continue
+			}
+			for _, peer := range sampler.Sample(node, 1, map[int]bool{node: true}) {
+				if !n.down[peer] {
+					values[node] = (values[node] + values[peer]) / 2
+					values[peer] = values[node]
+				}
+			}
+		}
+	}
+	estimates := make([]int, len(values))
+	for node, v := range values {
+		estimates[node] = 1
+		if v > 0 {
+			estimates[node] = int(math.Round(1 / v))
+		}
+	}
+	return estimates
+}
+
+// runEpochOnceAdaptive is RunEpochNaiveOnce with fan-out of each sender
+// chosen by the rule, it also counts messages received by each node.
+func (n *Network) runEpochOnceAdaptive(epoch int, fanout func(node int) int) Stat {
+	var s Stat
+
+	if n.received == nil {
+		n.received = make(map[int]int, len(n.Topology))
+	}
+	newVotes := make(map[int]int, len(n.Topology))
+
+	for ind := 0; ind < len(n.Topology); ind++ {
+		if n.Topology[ind] == 1 {
+			if n.expired(ind) {
+				n.Topology[ind] = -1
+				continue
+			}
+			f := fanout(ind)
+			if f < 1 {
+				f = 1
+			}
+			voted := n.choose(ind, f)
+			n.SetHistoryEpoch(ind, epoch, voted)
+			s.Sent += len(voted)
+			s.Excluded += f - len(voted)
+			for _, vote := range voted {
+				newVotes[vote]++
+			}
+			n.Topology[ind] = -1
+		}
+	}
+
+	for node, repeated := range newVotes {
+		s.account(n.Topology[node] != 0, repeated)
+		n.received[node] += repeated
+		if n.Topology[node] == 0 {
+			n.Topology[node] = 1
+		}
+	}
+	s.Coverage = n.CountCoverage()
+	return s
+}
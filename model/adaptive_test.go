@@ -0,0 +1,57 @@
+package model
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNetwork_RunEpochAdaptive(t *testing.T) {
+	net, err := prepareNetwork(100)
+	require.NoError(t, err)
+	net.SetRandSource(rand.NewSource(1))
+	require.Equal(t, 5, net.RunEpochEpochOnce(5, 0).Sent)
+	s := net.RunEpochEpochOnce(5, 1)
+	require.Equal(t, 5*4, s.Sent)
+	s = net.RunEpochEpochOnce(5, 10)
+	require.Equal(t, s.Delivered+s.Stale+s.Concurrent, s.Sent) // fan-out 1 at least
+
+	net, err = prepareNetwork(100)
+	require.NoError(t, err)
+	require.Equal(t, 8, net.RunEpochDecayOnce(8, 0).Sent)
+	require.Equal(t, 8*6, net.RunEpochDecayOnce(8, 1).Sent)
+
+	net, err = prepareNetwork(100)
+	require.NoError(t, err)
+	net.SetRandSource(rand.NewSource(1))
+	require.Equal(t, 5+2, net.RunEpochLogSizeOnce(2, 0).Sent) // ceil(ln 100) = 5
+
+	// node received data twice sends one message less
+	net, err = prepareNetwork(10)
+	require.NoError(t, err)
+	net.Topology = map[int]int{0: -1, 1: 1, 2: 1, 3: 1, 4: 0, 5: 0, 6: 0, 7: 0, 8: 0, 9: 0}
+	net.received = map[int]int{1: 2, 2: 1, 3: 4}
+	require.Equal(t, 3+4+1, net.RunEpochDuplicateOnce(4, 1).Sent)
+}
+
+func TestNetwork_EstimateSize(t *testing.T) {
+	for _, membership := range []string{GlobalMembership, StaticMembership, CyclonMembership} {
+		net, err := prepareNetwork(1000)
+		require.NoError(t, err)
+		net.SetRandSource(rand.NewSource(1))
+		sampler, err := NewPeerSampler(membership, &net, 10)
+		require.NoError(t, err)
+		net.SetPeerSampler(sampler)
+		for node := range net.Topology {
+			require.InDelta(t, 1000, net.EstimateSize(node), 100, membership)
+		}
+	}
+
+	// nodes of the other half never exchange values with node with data
+	net, err := prepareNetwork(10)
+	require.NoError(t, err)
+	net.SetPeerSampler(&Static{net: &net, views: [][]int{{1}, {0}, {3}, {2}, {0}, {0}, {7}, {6}, {9}, {8}}})
+	require.Equal(t, 1, net.EstimateSize(2))
+	require.InDelta(t, 4, net.EstimateSize(0), 1)
+}
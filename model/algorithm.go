@@ -0,0 +1,79 @@
+package model
+
+import (
+	"errors"
+	"sort"
+)
+
+type (
+	// Algorithm processes single propagation epoch of the network.
+	Algorithm func(n *Network, fanout int, epoch int) Stat
+
+	// Quiescence reports that algorithm will not change the network
+	// anymore, so propagation is over even if the network is not filled.
+	Quiescence func(n Network) bool
+
+	algorithm struct {
+		run       Algorithm
+		quiescent Quiescence
+	}
+)
+
+const DefaultAlgorithm = "naive-once"
+
+var (
+	ErrUnknownAlgorithm = errors.New("unknown algorithm")
+
+	algorithms = map[string]algorithm{
+		"naive-once":             {(*Network).RunEpochNaiveOnce, Network.IsQuiescentOnce},
+		"naive-forever":          {(*Network).RunEpochNaiveForever, never},
+		"naive-forever-memorise": {(*Network).RunEpochNaiveForeverMemorise, Network.IsQuiescentMemorise},
+		"centralised":            {(*Network).RunEpochCentralised, never},
+		"centralised-memorise":   {(*Network).RunEpochCentralisedMemorise, Network.IsQuiescentCentralised},
+		"vector-once":            {(*Network).RunEpochVectorOnce, Network.IsQuiescentOnce},
+		"epoch-once":             {(*Network).RunEpochEpochOnce, Network.IsQuiescentOnce},
+		"decay-once":             {(*Network).RunEpochDecayOnce, Network.IsQuiescentOnce},
+		"log-size-once":          {(*Network).RunEpochLogSizeOnce, Network.IsQuiescentOnce},
+		"duplicate-once":         {(*Network).RunEpochDuplicateOnce, Network.IsQuiescentOnce},
+		"gossip1":                {(*Network).RunEpochGossip1, Network.IsQuiescentOnce},
+		"gossip2":                {(*Network).RunEpochGossip2, Network.IsQuiescentOnce},
+		"gossip-edge":            {(*Network).RunEpochGossipEdge, Network.IsQuiescentOnce},
+		"push-pull":              {(*Network).RunEpochPushPull, Network.IsQuiescentOnce},
+	}
+)
+
+// GetAlgorithm returns algorithm by its name, empty name stands for
+// DefaultAlgorithm.
+func GetAlgorithm(name string) (Algorithm, error) {
+	if name == "" {
+		name = DefaultAlgorithm
+	}
+	alg, ok := algorithms[name]
+	if !ok {
+		return nil, ErrUnknownAlgorithm
+	}
+	return alg.run, nil
+}
+
+// GetQuiescence returns quiescence check of algorithm by its name, empty
+// name stands for DefaultAlgorithm.
+func GetQuiescence(name string) (Quiescence, error) {
+	if name == "" {
+		name = DefaultAlgorithm
+	}
+	alg, ok := algorithms[name]
+	if !ok {
+		return nil, ErrUnknownAlgorithm
+	}
+	return alg.quiescent, nil
+}
+
+// AlgorithmNames returns sorted list of available algorithms.
+func AlgorithmNames() []string {
+	names := make([]string, 0, len(algorithms))
+	for name := range algorithms {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,151 @@
+package model
+
+/*
+	Cyclon (Voulgaris, Gavidia and van Steen) peer sampling. Every node
+	keeps view of size peers with their ages. In a round every node in id
+	order increases ages, removes its oldest peer Q from the view and sends
+	Q itself with age 0 and half of view size minus one other random peers.
+	Q replies with the same number of random peers, both add received peers
+	they do not know yet to free slots first, then instead of peers they
+	sent. Crashed Q does not reply, so it is dropped from the view.
+	Views start as random nodes, e.g. given by random walks of joins.
+*/
+
+type (
+	// Cyclon keeps views of peers with ages of every node.
+	Cyclon struct {
+		net     *Network
+		views   [][]cyclonEntry
+		size    int
+		crashed map[int]bool
+	}
+
+	cyclonEntry struct {
+		node int
+		age  int
+	}
+)
+
+// NewCyclon creates views of size random peers and runs shuffle rounds.
+// Random source of the network is used.
+func NewCyclon(n *Network, size int) *Cyclon {
+	c := &Cyclon{
+		net:     n,
+		views:   make([][]cyclonEntry, len(n.Topology)),
+		size:    size,
+		crashed: make(map[int]bool),
+	}
+	for node := range c.views {
+		for _, peer := range n.ChooseNodesCheck(size, map[int]bool{node: true}) {
+			c.views[node] = append(c.views[node], cyclonEntry{node: peer})
+		}
+	}
+	c.rounds(samplerRounds)
+	return c
+}
+
+func (c *Cyclon) Sample(node, count int, exclude map[int]bool) []int {
+	return sampleView(c.net, c.View(node), count, exclude)
+}
+
+func (c *Cyclon) View(node int) []int {
+	view := make([]int, len(c.views[node]))
+	for i, e := range c.views[node] {
+		view[i] = e.node
+	}
+	return view
+}
+
+// Crash stops the nodes and runs shuffle rounds, which drop them.
+func (c *Cyclon) Crash(nodes []int) {
+	for _, node := range nodes {
+		c.crashed[node] = true
+	}
+	c.rounds(samplerRounds)
+}
+
+func (c *Cyclon) rounds(num int) {
+	for i := 0; i < num; i++ {
+		for node := range c.views {
+			if !c.crashed[node] {
+				c.shuffle(node)
+			}
+		}
+	}
+}
+
+func (c *Cyclon) shuffle(p int) {
+	view := c.views[p]
+	if len(view) == 0 {
+		return
+	}
+	oldest := 0
+	for i := range view {
+		view[i].age++
+		if view[i].age > view[oldest].age {
+			oldest = i
+		}
+	}
+	q := view[oldest].node
+	c.views[p] = append(view[:oldest], view[oldest+1:]...)
+	if c.crashed[q] {
+		return
+	}
+
+	length := c.size / 2
+	if length < 1 {
+		length = 1
+	}
+	sent := append([]cyclonEntry{{node: p}}, c.sample(p, length-1)...)
+	reply := c.sample(q, length)
+	c.merge(q, sent, reply)
+	c.merge(p, reply, sent[1:])
+}
+
+// sample returns up to count random entries of the node view.
+func (c *Cyclon) sample(node, count int) []cyclonEntry {
+	var entries []cyclonEntry
+	for _, i := range c.net.perm(len(c.views[node])) {
+		if len(entries) == count {
+			break
+		}
+		entries = append(entries, c.views[node][i])
+	}
+	return entries
+}
+
+// merge adds received entries to the node view: to free slots first, then
+// instead of sent entries.
+func (c *Cyclon) merge(node int, received, sent []cyclonEntry) {
+	for _, e := range received {
+		if e.node == node || c.knows(node, e.node) {
+			continue
+		}
+		if len(c.views[node]) < c.size {
+			c.views[node] = append(c.views[node], e)
+			continue
+		}
+		for len(sent) > 0 {
+			i := c.index(node, sent[0].node)
+			sent = sent[1:]
+			if i >= 0 {
+				c.views[node][i] = e
+				break
+			}
+		}
+	}
+}
+
+func (c *Cyclon) knows(node, peer int) bool {
+	return c.index(node, peer) >= 0
+}
+
+// index returns position of the peer in the node view, -1 if it is absent.
+func (c *Cyclon) index(node, peer int) int {
+	for i, e := range c.views[node] {
+		if e.node == peer {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,122 @@
+package model
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+)
+
+/*
+	Export of propagation graph stored in Network.History in Graphviz DOT format.
+	Nodes are colored by the epoch when they got data, edges are styled by
+	their usefulness:
+	`- solid         : first message which delivered data to the node
+	`- dashed        : duplicate delivered to the node in the same epoch
+	`- dotted, grey  : message sent to the node which already had data
+*/
+
+const dotPalette = 9 // number of colors in `blues9` graphviz color scheme
+
+// InfectionEpochs returns the hop number when each node got data.
+// Nodes that started propagation have zero hop, uninformed nodes
+// are absent in the result.
+func (n Network) InfectionEpochs() map[int]int {
+	infected := make(map[int]int, len(n.Topology))
+	for _, epoch := range n.historyEpochs() {
+		for _, sender := range sortedKeys(n.History[epoch]) {
+			if _, ok := infected[sender]; !ok {
+				infected[sender] = 0
+			}
+		}
+		for _, sender := range sortedKeys(n.History[epoch]) {
+			for _, node := range n.History[epoch][sender] {
+				if _, ok := infected[node]; !ok {
+					infected[node] = epoch + 1
+				}
+			}
+		}
+	}
+	return infected
+}
+
+// WriteDOT writes dissemination graph of the experiment to w.
+func (n Network) WriteDOT(w io.Writer) error {
+	buf := bufio.NewWriter(w)
+	infected := n.InfectionEpochs()
+
+	fmt.Fprintln(buf, "digraph gossip {")
+	fmt.Fprintln(buf, "  node [shape=circle style=filled colorscheme=blues9];")
+
+	nodes := sortedKeys(n.Topology)
+	for _, node := range nodes {
+		hop, ok := infected[node]
+		if !ok {
+			fmt.Fprintf(buf, "  %d [fillcolor=white color=red label=\"%d\\n-\"];\n", node, node)
+			continue
+		}
+		color := hop + 1
+		if color > dotPalette {
+			color = dotPalette
+		}
+		fontcolor := "black"
+		if color > dotPalette/2 {
+			fontcolor = "white"
+		}
+		fmt.Fprintf(buf, "  %d [fillcolor=%d fontcolor=%s label=\"%d\\n%d\"];\n",
+			node, color, fontcolor, node, hop)
+	}
+
+	delivered := make(map[int]bool, len(n.Topology))
+	for _, epoch := range n.historyEpochs() {
+		for _, sender := range sortedKeys(n.History[epoch]) {
+			delivered[sender] = true
+		}
+		newly := make(map[int]bool)
+		for _, sender := range sortedKeys(n.History[epoch]) {
+			for _, node := range n.History[epoch][sender] {
+				var style string
+				switch {
+				case delivered[node]:
+					style = "style=dotted color=grey"
+				case newly[node]:
+					style = "style=dashed"
+				default:
+					style = "style=solid"
+					newly[node] = true
+				}
+				fmt.Fprintf(buf, "  %d -> %d [%s label=%d];\n", sender, node, style, epoch+1)
+			}
+		}
+		for node := range newly {
+			delivered[node] = true
+		}
+	}
+
+	fmt.Fprintln(buf, "}")
+	return buf.Flush()
+}
+
+func (n Network) historyEpochs() []int {
+	return sortedKeys(n.History)
+}
+
+func sortedKeys(m interface{}) []int {
+	var keys []int
+	switch v := m.(type) {
+	case map[int]int:
+		for k := range v {
+			keys = append(keys, k)
+		}
+	case map[int][]int:
+		for k := range v {
+			keys = append(keys, k)
+		}
+	case map[int]map[int][]int:
+		for k := range v {
+			keys = append(keys, k)
+		}
+	}
+	sort.Ints(keys)
+	return keys
+}
@@ -0,0 +1,28 @@
+package model
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNetwork_WriteDOT(t *testing.T) {
+	net, err := prepareNetwork(4)
+	require.NoError(t, err)
+
+	net.Topology = map[int]int{0: -1, 1: -1, 2: -1, 3: 0}
+	net.SetHistoryEpoch(0, 0, []int{1, 2})
+	net.SetHistoryEpoch(1, 1, []int{2, 0})
+	net.SetHistoryEpoch(2, 1, []int{0})
+
+	require.Equal(t, map[int]int{0: 0, 1: 1, 2: 1}, net.InfectionEpochs())
+
+	buf := new(bytes.Buffer)
+	require.NoError(t, net.WriteDOT(buf))
+	out := buf.String()
+
+	require.Contains(t, out, "0 -> 1 [style=solid label=1]")
+	require.Contains(t, out, "1 -> 2 [style=dotted color=grey label=2]")
+	require.Contains(t, out, "3 [fillcolor=white color=red")
+}
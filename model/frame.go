@@ -0,0 +1,29 @@
+package model
+
+type (
+	// Frame is a snapshot of the network after single epoch, used to
+	// replay propagation process step by step.
+	Frame struct {
+		Epoch    int           // number of the epoch, -1 for initial state
+		Topology map[int]int   // node states after the epoch
+		Sent     map[int][]int // nodes chosen by each sender in the epoch
+		Stat     Stat          // statistics of the epoch
+	}
+)
+
+// Frame returns snapshot of the network after the epoch with its stat.
+func (n Network) Frame(epoch int, s Stat) Frame {
+	f := Frame{
+		Epoch:    epoch,
+		Topology: make(map[int]int, len(n.Topology)),
+		Sent:     make(map[int][]int, len(n.History[epoch])),
+		Stat:     s,
+	}
+	for k, v := range n.Topology {
+		f.Topology[k] = v
+	}
+	for k, v := range n.History[epoch] {
+		f.Sent[k] = append([]int(nil), v...)
+	}
+	return f
+}
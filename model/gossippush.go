@@ -3,6 +3,8 @@ package model
 /*
 	Here defined different algorithms for push gossip processing
 	In model we use RunEpochNaiveOnce algorithm
+	Senders are processed in order of their ids, so propagation is
+	reproducible with seeded random source of the network
 */
 
 //	If node has data, choose F other nodes and propagate info. Do it once in lifetime.
@@ -15,11 +17,16 @@ func (n *Network) RunEpochNaiveOnce(fanout int, epoch int) Stat {
 
 	newVotes := make(map[int]int, len(n.Topology))
 
-	for ind, v := range n.Topology {
-		if v == 1 {
-			voted := n.ChooseNodesCheck(fanout, n.generated[ind])
+	for ind := 0; ind < len(n.Topology); ind++ {
+		if n.Topology[ind] == 1 {
+			if n.expired(ind) {
+				n.Topology[ind] = -1
+				continue
+			}
+			voted := n.choose(ind, fanout)
 			n.SetHistoryEpoch(ind, epoch, voted)
 			s.Sent += len(voted)
+			s.Excluded += fanout - len(voted)
 			for _, vote := range voted {
 				newVotes[vote]++
 			}
@@ -28,14 +35,10 @@ func (n *Network) RunEpochNaiveOnce(fanout int, epoch int) Stat {
 	}
 
 	for node, repeated := range newVotes {
-		if n.Topology[node] != 0 {
-			s.Reused++
-		} else {
+		s.account(n.Topology[node] != 0, repeated)
+		if n.Topology[node] == 0 {
 			n.Topology[node] = 1
 		}
-		if repeated > 1 {
-			s.Reused += repeated - 1
-		}
 	}
 	s.Coverage = n.CountCoverage()
 	return s
@@ -55,24 +58,23 @@ func (n *Network) RunEpochNaiveForever(fanout int, epoch int) Stat {
 
 	newVotes := make(map[int]int, len(n.Topology))
 
-	for ind, v := range n.Topology {
-		if v == 1 {
-			voted := n.ChooseNodesCheck(fanout, n.generated[ind])
+	for ind := 0; ind < len(n.Topology); ind++ {
+		if n.Topology[ind] == 1 {
+			if n.expired(ind) {
+				continue
+			}
+			voted := n.choose(ind, fanout)
 			n.SetHistoryEpoch(ind, epoch, voted)
 			s.Sent += len(voted)
+			s.Excluded += fanout - len(voted)
 			for _, vote := range voted {
 				newVotes[vote]++
 			}
 		}
 	}
 	for node, repeated := range newVotes {
-		if n.Topology[node] == 1 {
-			s.Reused++
-		}
+		s.account(n.Topology[node] != 0, repeated)
 		n.Topology[node] = 1
-		if repeated > 1 {
-			s.Reused += repeated - 1
-		}
 	}
 	s.Coverage = n.CountCoverage()
 	return s
@@ -88,11 +90,15 @@ func (n *Network) RunEpochNaiveForeverMemorise(fanout int, epoch int) Stat {
 
 	newVotes := make(map[int]int, len(n.Topology))
 
-	for ind, v := range n.Topology {
-		if v == 1 {
-			voted := n.ChooseNodesCheck(fanout, n.generated[ind])
+	for ind := 0; ind < len(n.Topology); ind++ {
+		if n.Topology[ind] == 1 {
+			if n.expired(ind) {
+				continue
+			}
+			voted := n.choose(ind, fanout)
 			n.SetHistoryEpoch(ind, epoch, voted)
 			s.Sent += len(voted)
+			s.Excluded += fanout - len(voted)
 			for _, vote := range voted {
 				n.generated[ind][vote] = true
 				newVotes[vote]++
@@ -100,13 +106,8 @@ func (n *Network) RunEpochNaiveForeverMemorise(fanout int, epoch int) Stat {
 		}
 	}
 	for node, repeated := range newVotes {
-		if n.Topology[node] == 1 {
-			s.Reused++
-		}
+		s.account(n.Topology[node] != 0, repeated)
 		n.Topology[node] = 1
-		if repeated > 1 {
-			s.Reused += repeated - 1
-		}
 	}
 	s.Coverage = n.CountCoverage()
 	return s
@@ -121,21 +122,17 @@ func (n *Network) RunEpochCentralised(fanout int, epoch int) Stat {
 
 	newVotes := make(map[int]int, len(n.Topology))
 
-	voted := n.ChooseNodesCheck(fanout, n.generated[0])
+	voted := n.choose(0, fanout)
 	n.SetHistoryEpoch(0, epoch, voted)
 	s.Sent += len(voted)
+	s.Excluded += fanout - len(voted)
 	for _, vote := range voted {
 		newVotes[vote]++
 	}
 
 	for node, repeated := range newVotes {
-		if n.Topology[node] == 1 {
-			s.Reused++
-		}
+		s.account(n.Topology[node] != 0, repeated)
 		n.Topology[node] = 1
-		if repeated > 1 {
-			s.Reused += repeated - 1
-		}
 	}
 	s.Coverage = n.CountCoverage()
 	return s
@@ -150,22 +147,18 @@ func (n *Network) RunEpochCentralisedMemorise(fanout int, epoch int) Stat {
 
 	newVotes := make(map[int]int, len(n.Topology))
 
-	voted := n.ChooseNodesCheck(fanout, n.generated[0])
+	voted := n.choose(0, fanout)
 	n.SetHistoryEpoch(0, epoch, voted)
 	s.Sent += len(voted)
+	s.Excluded += fanout - len(voted)
 	for _, vote := range voted {
 		newVotes[vote]++
 	}
 
 	for node, repeated := range newVotes {
-		if n.Topology[node] == 1 {
-			s.Reused++
-		}
+		s.account(n.Topology[node] != 0, repeated)
 		n.Topology[node] = 1
 		n.generated[0][node] = true
-		if repeated > 1 {
-			s.Reused += repeated - 1
-		}
 	}
 	s.Coverage = n.CountCoverage()
 	return s
@@ -183,11 +176,16 @@ func (n *Network) RunEpochVectorOnce(fanout int, epoch int) Stat {
 	newVotes := make(map[int]int, len(n.Topology))
 	voters := make(map[int][]int, len(n.Topology))
 
-	for ind, v := range n.Topology {
-		if v == 1 {
-			voted := n.ChooseNodesCheck(fanout, n.generated[ind])
+	for ind := 0; ind < len(n.Topology); ind++ {
+		if n.Topology[ind] == 1 {
+			if n.expired(ind) {
+				n.Topology[ind] = -1
+				continue
+			}
+			voted := n.choose(ind, fanout)
 			n.SetHistoryEpoch(ind, epoch, voted)
 			s.Sent += len(voted)
+			s.Excluded += fanout - len(voted)
 			for _, vote := range voted {
 				newVotes[vote]++
 
@@ -201,18 +199,14 @@ func (n *Network) RunEpochVectorOnce(fanout int, epoch int) Stat {
 	}
 
 	for node, repeated := range newVotes {
-		if n.Topology[node] != 0 {
-			s.Reused++
-		} else {
+		s.account(n.Topology[node] != 0, repeated)
+		if n.Topology[node] == 0 {
 			voter := voters[node][0]
 			for k := range n.generated[voter] {
 				n.generated[node][k] = true
 			}
 			n.Topology[node] = 1
 		}
-		if repeated > 1 {
-			s.Reused += repeated - 1
-		}
 	}
 	s.Coverage = n.CountCoverage()
 	return s
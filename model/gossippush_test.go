@@ -1,6 +1,7 @@
 package model
 
 import (
+	"math/rand"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -43,3 +44,100 @@ func TestNetwork_ChooseNodesCheck(t *testing.T) {
 	require.Contains(t, r1, 5)
 	require.Contains(t, r1, 8)
 }
+
+func TestNetwork_SetRandSource(t *testing.T) {
+	run := func() map[int]map[int][]int {
+		net, err := prepareNetwork(50)
+		require.NoError(t, err)
+		net.SetRandSource(rand.NewSource(42))
+		for i := 0; i < 5; i++ {
+			net.RunEpochNaiveOnce(3, i)
+		}
+		return net.History
+	}
+	require.Equal(t, run(), run())
+}
+
+func TestNetwork_random(t *testing.T) {
+	net, err := prepareNetwork(10)
+	require.NoError(t, err)
+	other, err := prepareNetwork(10)
+	require.NoError(t, err)
+
+	// networks without source do not share crypto source
+	require.True(t, net.random() == net.random())
+	require.False(t, net.random() == other.random())
+}
+
+func TestAntitheticSource(t *testing.T) {
+	net, err := prepareNetwork(100)
+	require.NoError(t, err)
+	anti, err := prepareNetwork(100)
+	require.NoError(t, err)
+
+	net.SetRandSource(rand.NewSource(1))
+	anti.SetRandSource(AntitheticSource{Source: rand.NewSource(1)})
+	for i := 0; i < 100; i++ {
+		require.Equal(t, 99, net.intn(100)+anti.intn(100))
+	}
+}
+
+func TestNetwork_IsQuiescent(t *testing.T) {
+	net, err := prepareNetwork(10)
+	require.NoError(t, err)
+	require.False(t, net.IsQuiescentOnce())
+	net.RunEpochNaiveOnce(2, 0)
+	net.RunEpochNaiveOnce(2, 1)
+	for epoch := 2; !net.IsQuiescentOnce(); epoch++ {
+		require.True(t, epoch < 10)
+		net.RunEpochNaiveOnce(2, epoch)
+	}
+
+	net, err = prepareNetwork(4)
+	require.NoError(t, err)
+	require.False(t, net.IsQuiescentMemorise())
+	require.False(t, net.IsQuiescentCentralised())
+	for epoch := 0; !net.IsQuiescentMemorise(); epoch++ {
+		// every node sends data to other 3 nodes in 3 epochs after it got it
+		require.True(t, epoch < 12)
+		net.RunEpochNaiveForeverMemorise(1, epoch)
+	}
+	require.True(t, net.IsNetworkFilled())
+}
+
+func TestStat_Accounting(t *testing.T) {
+	net, err := prepareNetwork(3)
+	require.NoError(t, err)
+	require.Equal(t, Stat{Sent: 2, Coverage: 3, Delivered: 2}, net.RunEpochNaiveOnce(2, 0))
+	require.Equal(t, Stat{Sent: 4, Coverage: 3, Reused: 4, Stale: 4}, net.RunEpochNaiveOnce(2, 1))
+
+	// algorithms where sender does not always use fixed fan-out
+	variable := map[string]bool{"epoch-once": true, "decay-once": true, "log-size-once": true, "duplicate-once": true,
+		"gossip1": true, "gossip2": true, "gossip-edge": true, "push-pull": true}
+	for _, name := range AlgorithmNames() {
+		alg, err := GetAlgorithm(name)
+		require.NoError(t, err)
+		net, err := prepareNetwork(30)
+		require.NoError(t, err)
+		net.SetRandSource(rand.NewSource(1))
+
+		for epoch := 0; epoch < 30 && !net.IsNetworkFilled(); epoch++ {
+			senders, before := 0, net.CountCoverage()
+			for _, v := range net.Topology {
+				if v == 1 {
+					senders++
+				}
+			}
+			if name == "centralised" || name == "centralised-memorise" {
+				senders = 1
+			}
+			s := alg(&net, 4, epoch)
+			require.Equal(t, s.Sent, s.Delivered+s.Stale+s.Concurrent, name)
+			require.Equal(t, s.Reused, s.Stale+s.Concurrent, name)
+			if !variable[name] {
+				require.Equal(t, senders*4, s.Sent+s.Excluded, name)
+			}
+			require.Equal(t, s.Coverage-before, s.Delivered, name)
+		}
+	}
+}
@@ -0,0 +1,285 @@
+package model
+
+/*
+	HyParView (Leitão, Pereira and Rodrigues) partial view membership. Every
+	node keeps small symmetric active view, which senders choose nodes from,
+	and larger passive view of backup peers:
+	`- join: new node connects to random contact, which sends FORWARDJOIN
+	   to its active peers; random walk of FORWARDJOIN adds new node to
+	   passive view at hop passiveWalk and to active view at its end
+	`- shuffle: node exchanges random sample of its views with node at the
+	   end of random walk, both add received nodes to passive views
+	`- repair: node with not full active view (e.g. lost crashed peer)
+	   promotes random passive peers, alive peer accepts NEIGHBOR request
+	   if it has free slot or requester has no active peers at all,
+	   crashed passive peers are dropped
+	Node connected to new active peer when its view is full disconnects
+	random active peer, both sides move each other to passive views.
+	Messages are modelled as instant operations processed in node id order.
+*/
+
+const (
+	activeWalk     = 6  // length of FORWARDJOIN and shuffle random walks
+	passiveWalk    = 3  // hop of FORWARDJOIN walk which adds node to passive view
+	shuffleActive  = 3  // active peers in shuffle sample
+	shufflePassive = 4  // passive peers in shuffle sample
+	passiveFactor  = 6  // size of passive view relative to active one
+	shuffleRounds  = 10 // shuffles of every node after join
+)
+
+type (
+	// HyParView keeps active and passive views of every node of the network.
+	HyParView struct {
+		net         *Network
+		active      [][]int // active views by node
+		passive     [][]int // passive views by node
+		crashed     map[int]bool
+		activeSize  int
+		passiveSize int
+	}
+)
+
+// NewHyParView joins nodes of the network one by one through random joined
+// contact and runs rounds of shuffles, nodes with not full active views
+// promote passive peers then. Random source of the network is used.
+func NewHyParView(net *Network, activeSize int) *HyParView {
+	h := &HyParView{
+		net:         net,
+		active:      make([][]int, len(net.Topology)),
+		passive:     make([][]int, len(net.Topology)),
+		crashed:     make(map[int]bool),
+		activeSize:  activeSize,
+		passiveSize: passiveFactor * activeSize,
+	}
+	for node := 1; node < len(net.Topology); node++ {
+		h.Join(node, net.intn(node))
+	}
+	for i := 0; i < shuffleRounds; i++ {
+		for node := 0; node < len(net.Topology); node++ {
+			h.Shuffle(node)
+		}
+	}
+	h.Repair()
+	return h
+}
+
+func (h *HyParView) Sample(node, count int, exclude map[int]bool) []int {
+	return sampleView(h.net, h.active[node], count, exclude)
+}
+
+// View returns active view of the node.
+func (h *HyParView) View(node int) []int {
+	return h.Active(node)
+}
+
+// Crash stops the nodes and repairs views.
+func (h *HyParView) Crash(nodes []int) {
+	for _, node := range nodes {
+		h.crashed[node] = true
+	}
+	h.Repair()
+}
+
+// Active returns active view of the node.
+func (h *HyParView) Active(node int) []int {
+	return append([]int(nil), h.active[node]...)
+}
+
+// Passive returns passive view of the node.
+func (h *HyParView) Passive(node int) []int {
+	return append([]int(nil), h.passive[node]...)
+}
+
+// Join connects new node to the contact and sends FORWARDJOIN to active
+// peers of the contact.
+func (h *HyParView) Join(node, contact int) {
+	h.connect(node, contact)
+	for _, peer := range h.Active(contact) {
+		if peer != node {
+			h.forwardJoin(peer, node, contact, activeWalk)
+		}
+	}
+}
+
+func (h *HyParView) forwardJoin(node, joined, sender, ttl int) {
+	if ttl == 0 || len(h.active[node]) == 1 {
+		h.connect(node, joined)
+		return
+	}
+	if ttl == passiveWalk {
+		h.addPassive(node, joined)
+	}
+	next, ok := h.randomPeer(h.active[node], sender, joined)
+	if !ok {
+		h.connect(node, joined)
+		return
+	}
+	h.forwardJoin(next, joined, node, ttl-1)
+}
+
+// Shuffle exchanges random sample of views of the node with node at the
+// end of random walk through active views.
+func (h *HyParView) Shuffle(node int) {
+	if h.crashed[node] || len(h.active[node]) == 0 {
+		return
+	}
+	sample := append([]int{node}, h.sample(h.active[node], shuffleActive)...)
+	sample = append(sample, h.sample(h.passive[node], shufflePassive)...)
+
+	target, prev := node, -1
+	for ttl := activeWalk; ttl > 0; ttl-- {
+		next, ok := h.randomPeer(h.active[target], prev, node)
+		if !ok {
+			break
+		}
+		prev, target = target, next
+	}
+	if target == node {
+		return
+	}
+	reply := h.sample(h.passive[target], len(sample))
+	for _, peer := range sample {
+		h.addPassive(target, peer)
+	}
+	for _, peer := range reply {
+		h.addPassive(node, peer)
+	}
+}
+
+// Repair drops crashed active peers of every alive node and fills not full
+// active views with passive peers.
+func (h *HyParView) Repair() {
+	for node := range h.active {
+		if h.crashed[node] {
+			continue
+		}
+		for _, peer := range h.Active(node) {
+			if h.crashed[peer] {
+				h.active[node] = remove(h.active[node], peer)
+			}
+		}
+		h.promote(node)
+	}
+}
+
+// promote sends NEIGHBOR requests to random passive peers until the active
+// view is full or there are no candidates left.
+func (h *HyParView) promote(node int) {
+	var tried []int
+	for len(h.active[node]) < h.activeSize {
+		var candidates []int
+		for _, peer := range h.passive[node] {
+			if !contains(tried, peer) {
+				candidates = append(candidates, peer)
+			}
+		}
+		peer, ok := h.randomPeer(candidates, -1, -1)
+		if !ok {
+			return
+		}
+		tried = append(tried, peer)
+		if h.crashed[peer] {
+			h.passive[node] = remove(h.passive[node], peer)
+			continue
+		}
+		if len(h.active[node]) == 0 || len(h.active[peer]) < h.activeSize {
+			h.connect(node, peer)
+		}
+	}
+}
+
+// connect adds symmetric active link, full views drop random active peers.
+func (h *HyParView) connect(a, b int) {
+	if a == b || contains(h.active[a], b) {
+		return
+	}
+	h.makeRoom(a, b)
+	h.makeRoom(b, a)
+	h.active[a] = append(h.active[a], b)
+	h.active[b] = append(h.active[b], a)
+	h.passive[a] = remove(h.passive[a], b)
+	h.passive[b] = remove(h.passive[b], a)
+}
+
+// makeRoom disconnects random active peer of full view of the node.
+func (h *HyParView) makeRoom(node, except int) {
+	if len(h.active[node]) < h.activeSize {
+		return
+	}
+	peer, ok := h.randomPeer(h.active[node], except, -1)
+	if !ok {
+		return
+	}
+	h.active[node] = remove(h.active[node], peer)
+	h.active[peer] = remove(h.active[peer], node)
+	h.addPassive(node, peer)
+	h.addPassive(peer, node)
+}
+
+// addPassive adds peer to passive view of the node, full view drops random
+// passive peer.
+func (h *HyParView) addPassive(node, peer int) {
+	if node == peer || contains(h.active[node], peer) || contains(h.passive[node], peer) {
+		return
+	}
+	if len(h.passive[node]) >= h.passiveSize {
+		i := h.net.intn(len(h.passive[node]))
+		h.passive[node] = append(h.passive[node][:i], h.passive[node][i+1:]...)
+	}
+	h.passive[node] = append(h.passive[node], peer)
+}
+
+// randomPeer returns random peer of the view except given ones.
+func (h *HyParView) randomPeer(view []int, except1, except2 int) (int, bool) {
+	n := 0
+	for _, peer := range view {
+		if peer != except1 && peer != except2 {
+			n++
+		}
+	}
+	if n == 0 {
+		return 0, false
+	}
+	i := h.net.intn(n)
+	for _, peer := range view {
+		if peer == except1 || peer == except2 {
+			continue
+		}
+		if i == 0 {
+			return peer, true
+		}
+		i--
+	}
+	return 0, false
+}
+
+// sample returns up to size random peers of the view.
+func (h *HyParView) sample(view []int, size int) []int {
+	var nodes []int
+	for _, i := range h.net.perm(len(view)) {
+		if len(nodes) == size {
+			break
+		}
+		nodes = append(nodes, view[i])
+	}
+	return nodes
+}
+
+func contains(view []int, node int) bool {
+	for _, peer := range view {
+		if peer == node {
+			return true
+		}
+	}
+	return false
+}
+
+// remove deletes node from the view keeping order of other peers.
+func remove(view []int, node int) []int {
+	for i, peer := range view {
+		if peer == node {
+			return append(view[:i], view[i+1:]...)
+		}
+	}
+	return view
+}
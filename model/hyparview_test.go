@@ -0,0 +1,81 @@
+package model
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHyParView(t *testing.T) {
+	net, err := prepareNetwork(200)
+	require.NoError(t, err)
+	net.SetRandSource(rand.NewSource(1))
+	h := NewHyParView(&net, 5)
+	checkViews(t, h)
+
+	// senders choose from active views only
+	net.SetPeerSampler(h)
+	s := net.RunEpochNaiveOnce(3, 0)
+	require.Equal(t, 3, s.Sent)
+	for _, node := range net.History[0][0] {
+		require.Contains(t, h.Active(0), node)
+	}
+
+	// crashed nodes are replaced in active views and never get data
+	net, err = prepareNetwork(200)
+	require.NoError(t, err)
+	net.SetRandSource(rand.NewSource(1))
+	h = NewHyParView(&net, 5)
+	net.SetPeerSampler(h)
+	crashed := net.CrashRandomNodes(40, 0)
+	require.Len(t, crashed, 40)
+	require.Equal(t, 160, net.Alive())
+	checkViews(t, h)
+	for len(net.History) < 200 && !net.IsQuiescentOnce() {
+		net.RunEpochNaiveOnce(3, len(net.History))
+	}
+	for _, node := range crashed {
+		require.Equal(t, 0, net.Topology[node])
+	}
+}
+
+// checkViews checks that active views of alive nodes are symmetric, not
+// empty, within size and connect all alive nodes.
+func checkViews(t *testing.T, h *HyParView) {
+	for node := range h.active {
+		if h.crashed[node] {
+			continue
+		}
+		require.NotEmpty(t, h.active[node])
+		require.True(t, len(h.active[node]) <= h.activeSize)
+		require.True(t, len(h.passive[node]) <= h.passiveSize)
+		for _, peer := range h.active[node] {
+			require.False(t, h.crashed[peer])
+			require.Contains(t, h.active[peer], node)
+			require.NotContains(t, h.passive[node], peer)
+		}
+		require.NotContains(t, h.passive[node], node)
+	}
+
+	seen := map[int]bool{}
+	queue := []int{}
+	for node := range h.active {
+		if !h.crashed[node] {
+			queue = append(queue, node)
+			seen[node] = true
+			break
+		}
+	}
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+		for _, peer := range h.active[node] {
+			if !seen[peer] {
+				seen[peer] = true
+				queue = append(queue, peer)
+			}
+		}
+	}
+	require.Len(t, seen, len(h.active)-len(h.crashed))
+}
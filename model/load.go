@@ -0,0 +1,44 @@
+package model
+
+type (
+	// Load is a number of messages sent and received by each node during
+	// the experiment, slices are indexed by node id.
+	Load struct {
+		Sent       []int
+		Received   []int
+		Duplicates []int // received messages which did not deliver new data
+	}
+)
+
+// Load returns per-node message counters of the experiment from History.
+// Messages are classified as in WriteDOT: every message to node which
+// already had data or got it earlier in the same epoch is a duplicate.
+func (n Network) Load() Load {
+	l := Load{
+		Sent:       make([]int, len(n.Topology)),
+		Received:   make([]int, len(n.Topology)),
+		Duplicates: make([]int, len(n.Topology)),
+	}
+	delivered := make(map[int]bool, len(n.Topology))
+	for _, epoch := range n.historyEpochs() {
+		for sender, nodes := range n.History[epoch] {
+			delivered[sender] = true
+			l.Sent[sender] += len(nodes)
+		}
+		newly := make(map[int]bool)
+		for _, nodes := range n.History[epoch] {
+			for _, node := range nodes {
+				l.Received[node]++
+				if delivered[node] || newly[node] {
+					l.Duplicates[node]++
+				} else {
+					newly[node] = true
+				}
+			}
+		}
+		for node := range newly {
+			delivered[node] = true
+		}
+	}
+	return l
+}
@@ -0,0 +1,34 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNetwork_Load(t *testing.T) {
+	net, err := prepareNetwork(4)
+	require.NoError(t, err)
+
+	net.SetHistoryEpoch(0, 0, []int{1, 2})
+	net.SetHistoryEpoch(1, 1, []int{2, 0})
+	net.SetHistoryEpoch(2, 1, []int{0, 3})
+
+	l := net.Load()
+	require.Equal(t, []int{2, 2, 2, 0}, l.Sent)
+	require.Equal(t, []int{2, 1, 2, 1}, l.Received)
+	require.Equal(t, []int{2, 0, 1, 0}, l.Duplicates)
+
+	// duplicates are redundant messages of the algorithm
+	net, err = prepareNetwork(30)
+	require.NoError(t, err)
+	reused := 0
+	for epoch := 0; !net.IsQuiescentOnce(); epoch++ {
+		reused += net.RunEpochNaiveOnce(3, epoch).Reused
+	}
+	dups := 0
+	for _, d := range net.Load().Duplicates {
+		dups += d
+	}
+	require.Equal(t, reused, dups)
+}
@@ -0,0 +1,121 @@
+package model
+
+/*
+	Message loss models. Lost message is sent, but never delivered:
+	`- none    : reliable links
+	`- uniform : every message is lost independently with loss rate
+	`- burst   : Gilbert-Elliott channel of every sender, all messages sent
+	   in bad state are lost; bad state lasts burstLength messages on
+	   average and the long-run share of lost messages is the loss rate
+	   (up to 5/6, channel turns bad after every good message then)
+	Propagation algorithms do not lose messages, loss models are used by
+	SWIM failure detector.
+*/
+
+import (
+	"errors"
+	"sort"
+)
+
+const (
+	NoLoss      = "none"
+	UniformLoss = "uniform"
+	BurstLoss   = "burst"
+)
+
+// burstLength is a mean number of messages lost in a row by burst loss.
+const burstLength = 5
+
+type (
+	// Loss decides which messages are lost.
+	Loss interface {
+		// Lost reports that next message of the sender is lost.
+		Lost(sender int) bool
+	}
+
+	reliable struct{}
+
+	uniformLoss struct {
+		net  *Network
+		rate float64
+	}
+
+	burstLoss struct {
+		net   *Network
+		enter float64      // probability to turn bad after good message
+		leave float64      // probability to turn good after bad message
+		bad   map[int]bool // senders in bad state
+	}
+)
+
+var (
+	ErrUnknownLoss     = errors.New("unknown loss model")
+	ErrInvalidLossRate = errors.New("loss rate must be in [0, 1) range")
+
+	losses = map[string]func(n *Network, rate float64) Loss{
+		NoLoss:      func(*Network, float64) Loss { return reliable{} },
+		UniformLoss: func(n *Network, rate float64) Loss { return &uniformLoss{net: n, rate: rate} },
+		BurstLoss:   newBurstLoss,
+	}
+)
+
+// NewLoss creates loss model by its name with the loss rate, empty name
+// stands for none. Random source of the network is used.
+func NewLoss(name string, n *Network, rate float64) (Loss, error) {
+	if err := ValidLoss(name, rate); err != nil {
+		return nil, err
+	}
+	if name == "" {
+		name = NoLoss
+	}
+	return losses[name](n, rate), nil
+}
+
+// ValidLoss checks loss model name and the loss rate.
+func ValidLoss(name string, rate float64) error {
+	if _, ok := losses[name]; !ok && name != "" {
+		return ErrUnknownLoss
+	}
+	if rate < 0 || rate >= 1 {
+		return ErrInvalidLossRate
+	}
+	return nil
+}
+
+// LossNames returns sorted names of available loss models.
+func LossNames() []string {
+	names := make([]string, 0, len(losses))
+	for name := range losses {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (reliable) Lost(int) bool {
+	return false
+}
+
+func (l *uniformLoss) Lost(int) bool {
+	return l.net.random().Float64() < l.rate
+}
+
+func newBurstLoss(n *Network, rate float64) Loss {
+	leave := 1.0 / burstLength
+	return &burstLoss{
+		net:   n,
+		enter: rate * leave / (1 - rate),
+		leave: leave,
+		bad:   make(map[int]bool),
+	}
+}
+
+func (l *burstLoss) Lost(sender int) bool {
+	v := l.net.random().Float64()
+	if l.bad[sender] {
+		l.bad[sender] = v >= l.leave
+	} else {
+		l.bad[sender] = v < l.enter
+	}
+	return l.bad[sender]
+}
@@ -0,0 +1,48 @@
+package model
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoss(t *testing.T) {
+	net, err := prepareNetwork(10)
+	require.NoError(t, err)
+	net.SetRandSource(rand.NewSource(1))
+
+	l, err := NewLoss("", &net, 0.5)
+	require.NoError(t, err)
+	require.False(t, l.Lost(0))
+
+	// long-run share of lost messages is the loss rate, burst loss loses
+	// messages in a row
+	for _, name := range []string{UniformLoss, BurstLoss} {
+		l, err := NewLoss(name, &net, 0.1)
+		require.NoError(t, err)
+		lost, runs, prev := 0, 0, false
+		for i := 0; i < 100000; i++ {
+			v := l.Lost(0)
+			if v {
+				lost++
+				if !prev {
+					runs++
+				}
+			}
+			prev = v
+		}
+		require.InDelta(t, 10000, lost, 500, name)
+		if name == BurstLoss {
+			require.InDelta(t, burstLength, float64(lost)/float64(runs), 0.5)
+		} else {
+			require.InDelta(t, 1/0.9, float64(lost)/float64(runs), 0.1)
+		}
+	}
+
+	_, err = NewLoss("gilbert", &net, 0.1)
+	require.Equal(t, ErrUnknownLoss, err)
+	_, err = NewLoss(UniformLoss, &net, 1)
+	require.Equal(t, ErrInvalidLossRate, err)
+	require.Equal(t, ErrInvalidLossRate, ValidLoss("", -0.1))
+}
@@ -0,0 +1,218 @@
+package model
+
+/*
+	Membership defines nodes which senders choose from. PeerSampler gives
+	every sender random nodes of its view:
+	`- global    : the whole network, uniform random nodes
+	`- static    : fixed random view of every node
+	`- cyclon    : views shuffled with the oldest peer (Voulgaris et al.)
+	`- newscast  : freshest items of merged caches (Jelasity et al.)
+	`- hyparview : symmetric active views (Leitão et al.), see HyParView
+	Views of partial memberships hold view size nodes, fan-out+1 by default.
+
+	Crashed nodes do not get data and are excluded from choices of every
+	sender as with perfect failure detector of global membership, partial
+	views are repaired by the membership protocol. Network is filled when
+	all alive nodes have data.
+*/
+
+import (
+	"errors"
+	"sort"
+)
+
+const (
+	GlobalMembership    = "global"
+	StaticMembership    = "static"
+	CyclonMembership    = "cyclon"
+	NewscastMembership  = "newscast"
+	HyParViewMembership = "hyparview"
+)
+
+// samplerRounds is a number of exchange rounds of Cyclon and Newscast
+// after bootstrap and after crashes.
+const samplerRounds = 20
+
+type (
+	// PeerSampler provides nodes which senders choose from.
+	PeerSampler interface {
+		// Sample returns up to count random nodes of the node view except
+		// excluded ones.
+		Sample(node, count int, exclude map[int]bool) []int
+
+		// View returns current view of the node, nil for the whole network.
+		View(node int) []int
+
+		// Crash stops the nodes and lets membership repair views.
+		Crash(nodes []int)
+	}
+
+	// Uniform is a global membership: every node knows the whole network.
+	Uniform struct {
+		net *Network
+	}
+
+	// Static keeps fixed random view of every node, crashed nodes are not
+	// replaced.
+	Static struct {
+		net   *Network
+		views [][]int
+	}
+)
+
+var (
+	ErrUnknownMembership = errors.New("unknown membership")
+
+	samplers = map[string]func(n *Network, viewSize int) PeerSampler{
+		GlobalMembership:    func(n *Network, _ int) PeerSampler { return NewUniform(n) },
+		StaticMembership:    func(n *Network, size int) PeerSampler { return NewStatic(n, size) },
+		CyclonMembership:    func(n *Network, size int) PeerSampler { return NewCyclon(n, size) },
+		NewscastMembership:  func(n *Network, size int) PeerSampler { return NewNewscast(n, size) },
+		HyParViewMembership: func(n *Network, size int) PeerSampler { return NewHyParView(n, size) },
+	}
+)
+
+// NewPeerSampler creates membership by its name over the network, empty
+// name stands for global.
+func NewPeerSampler(name string, n *Network, viewSize int) (PeerSampler, error) {
+	if name == "" {
+		name = GlobalMembership
+	}
+	newSampler, ok := samplers[name]
+	if !ok {
+		return nil, ErrUnknownMembership
+	}
+	return newSampler(n, viewSize), nil
+}
+
+// ValidMembership checks membership name, empty name stands for global.
+func ValidMembership(name string) error {
+	if _, ok := samplers[name]; !ok && name != "" {
+		return ErrUnknownMembership
+	}
+	return nil
+}
+
+// MembershipNames returns sorted names of available memberships.
+func MembershipNames() []string {
+	names := make([]string, 0, len(samplers))
+	for name := range samplers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SetPeerSampler makes senders choose nodes from views of the sampler, nil
+// stands for global membership.
+func (n *Network) SetPeerSampler(s PeerSampler) {
+	n.sampler = s
+}
+
+// PeerSampler returns membership of the network.
+func (n *Network) PeerSampler() PeerSampler {
+	if n.sampler == nil {
+		return NewUniform(n)
+	}
+	return n.sampler
+}
+
+// choose returns up to fanout random nodes for sender except nodes
+// excluded by the algorithm.
+func (n *Network) choose(sender, fanout int) []int {
+	return n.PeerSampler().Sample(sender, fanout, n.generated[sender])
+}
+
+// exhausted reports that algorithm excluded all nodes sender can choose.
+func (n *Network) exhausted(sender int) bool {
+	view := n.PeerSampler().View(sender)
+	if view == nil {
+		return len(n.generated[sender]) >= len(n.Topology)
+	}
+	for _, peer := range view {
+		if !n.generated[sender][peer] {
+			return false
+		}
+	}
+	return true
+}
+
+// CrashRandomNodes crashes count random alive nodes except the given one
+// and lets membership repair views, returns crashed nodes.
+func (n *Network) CrashRandomNodes(count, except int) []int {
+	if n.down == nil {
+		n.down = make(map[int]bool, count)
+	}
+	var nodes []int
+	for len(nodes) < count && len(n.down) < len(n.Topology)-1 {
+		node := n.intn(len(n.Topology))
+		if node == except || n.down[node] {
+			continue
+		}
+		n.down[node] = true
+		for _, excluded := range n.generated {
+			excluded[node] = true
+		}
+		nodes = append(nodes, node)
+	}
+	n.PeerSampler().Crash(nodes)
+	return nodes
+}
+
+// Alive returns number of nodes which did not crash.
+func (n Network) Alive() int {
+	return len(n.Topology) - len(n.down)
+}
+
+// NewUniform creates global membership of the network.
+func NewUniform(n *Network) *Uniform {
+	return &Uniform{net: n}
+}
+
+func (u *Uniform) Sample(node, count int, exclude map[int]bool) []int {
+	return u.net.ChooseNodesCheck(count, exclude)
+}
+
+func (u *Uniform) View(int) []int {
+	return nil
+}
+
+func (u *Uniform) Crash([]int) {}
+
+// NewStatic gives every node of the network view of size random nodes.
+func NewStatic(n *Network, size int) *Static {
+	s := &Static{net: n, views: make([][]int, len(n.Topology))}
+	for node := range s.views {
+		s.views[node] = n.ChooseNodesCheck(size, map[int]bool{node: true})
+	}
+	return s
+}
+
+func (s *Static) Sample(node, count int, exclude map[int]bool) []int {
+	return sampleView(s.net, s.views[node], count, exclude)
+}
+
+func (s *Static) View(node int) []int {
+	return append([]int(nil), s.views[node]...)
+}
+
+func (s *Static) Crash([]int) {}
+
+// sampleView returns up to count random nodes of the view except excluded
+// ones.
+func sampleView(n *Network, view []int, count int, exclude map[int]bool) []int {
+	var peers []int
+	for _, peer := range view {
+		if !exclude[peer] {
+			peers = append(peers, peer)
+		}
+	}
+	var nodes []int
+	for _, i := range n.perm(len(peers)) {
+		if len(nodes) == count {
+			break
+		}
+		nodes = append(nodes, peers[i])
+	}
+	return nodes
+}
@@ -0,0 +1,83 @@
+package model
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPeerSampler(t *testing.T) {
+	// small caches of newscast split the network into cliques and keep
+	// items of crashed nodes
+	sizes := map[string]int{StaticMembership: 5, CyclonMembership: 5, NewscastMembership: 20, HyParViewMembership: 5}
+	for name, size := range sizes {
+		net, err := prepareNetwork(200)
+		require.NoError(t, err)
+		net.SetRandSource(rand.NewSource(1))
+		s, err := NewPeerSampler(name, &net, size)
+		require.NoError(t, err, name)
+		net.SetPeerSampler(s)
+
+		for node := range net.Topology {
+			view := s.View(node)
+			require.NotEmpty(t, view, name)
+			require.True(t, len(view) <= size, name)
+			require.NotContains(t, view, node, name)
+			sample := s.Sample(node, 3, map[int]bool{view[0]: true})
+			require.True(t, len(sample) <= 3, name)
+			require.NotContains(t, sample, view[0], name)
+			for _, peer := range sample {
+				require.Contains(t, view, peer, name)
+			}
+		}
+
+		crashed := net.CrashRandomNodes(20, 0)
+		for node := range net.Topology {
+			if net.down[node] || name == StaticMembership {
+				continue
+			}
+			for _, peer := range crashed {
+				require.NotContains(t, s.View(node), peer, name)
+			}
+		}
+	}
+
+	net, err := prepareNetwork(10)
+	require.NoError(t, err)
+	s, err := NewPeerSampler("", &net, 5)
+	require.NoError(t, err)
+	require.Nil(t, s.View(0))
+	require.Len(t, s.Sample(0, 3, map[int]bool{0: true}), 3)
+	_, err = NewPeerSampler("scamp", &net, 5)
+	require.Equal(t, ErrUnknownMembership, err)
+	require.Equal(t, ErrUnknownMembership, ValidMembership("scamp"))
+	require.NoError(t, ValidMembership(""))
+}
+
+func TestNetwork_Views(t *testing.T) {
+	net, err := prepareNetwork(10)
+	require.NoError(t, err)
+	_, ok := net.Views()
+	require.False(t, ok)
+
+	clustering := map[string]float64{}
+	for _, name := range []string{CyclonMembership, NewscastMembership} {
+		net, err := prepareNetwork(300)
+		require.NoError(t, err)
+		net.SetRandSource(rand.NewSource(1))
+		s, err := NewPeerSampler(name, &net, 5)
+		require.NoError(t, err)
+		net.SetPeerSampler(s)
+		v, ok := net.Views()
+		require.True(t, ok)
+		require.Len(t, v.InDegree, 300)
+		sum := 0
+		for _, in := range v.InDegree {
+			sum += in
+		}
+		require.Equal(t, 300*5, sum, name)
+		clustering[name] = v.Clustering
+	}
+	require.True(t, clustering[CyclonMembership] < clustering[NewscastMembership])
+}
@@ -1,12 +1,29 @@
 package model
 
-import "errors"
+import (
+	"errors"
+	mrand "math/rand"
+)
 
 type (
 	Network struct {
 		Topology  map[int]int           // defines map of available nodes
 		History   map[int]map[int][]int // history of all propagation changes
 		generated map[int]map[int]bool  // extra structure for history based algorithms.
+		rand      *mrand.Rand           // random source of the network, own crypto source if nil
+		received  map[int]int           // number of messages received by node, for adaptive algorithms
+		estimates []int                 // network size estimated by node, see EstimateSize
+
+		forward     float64 // probability of forwarding, see SetForwarding
+		forwardHops int     // number of the first hops which always forward
+		lowForward  float64 // probability of forwarding of low degree nodes, see SetLowDegreeForwarding
+		lowDegree   int     // nodes with less neighbors forward with lowForward
+
+		ttl  int         // limit of hops, see SetTTL
+		hops map[int]int // hop of the first message which brought data to node
+
+		sampler PeerSampler  // membership, see SetPeerSampler
+		down    map[int]bool // crashed nodes, see CrashRandomNodes
 	}
 )
 
@@ -19,11 +36,12 @@ func (n *Network) SetHistoryEpoch(id int, epoch int, history []int) {
 		n.History[epoch] = make(map[int][]int, len(n.Topology))
 	}
 	n.History[epoch][id] = history
+	n.trackHops(id, history)
 }
 
 func (n Network) IsNetworkFilled() bool {
-	for _, v := range n.Topology {
-		if v == 0 {
+	for id, v := range n.Topology {
+		if v == 0 && !n.down[id] {
 			return false
 		}
 	}
@@ -38,6 +56,54 @@ func (n *Network) VisitNode(i int) error {
 	return nil
 }
 
+// IsolateRandomNode chooses random node except the given one and excludes
+// it from choices of every sender, so the node never gets data. It is used
+// to sample rare propagation failures, returns the chosen node.
+func (n *Network) IsolateRandomNode(except int) int {
+	node := n.intn(len(n.Topology) - 1)
+	if node >= except {
+		node++
+	}
+	for _, excluded := range n.generated {
+		excluded[node] = true
+	}
+	return node
+}
+
+// IsQuiescentOnce reports that no node is ready to propagate data within
+// TTL, which ends propagation of algorithms sending data once in lifetime.
+func (n Network) IsQuiescentOnce() bool {
+	for id, v := range n.Topology {
+		if v == 1 && !n.expired(id) {
+			return false
+		}
+	}
+	return true
+}
+
+// IsQuiescentMemorise reports that every node with data has already sent
+// it to all nodes it can choose, which ends propagation of memorising
+// algorithms.
+func (n Network) IsQuiescentMemorise() bool {
+	for id, v := range n.Topology {
+		if v != 0 && !n.expired(id) && !n.exhausted(id) {
+			return false
+		}
+	}
+	return true
+}
+
+// IsQuiescentCentralised reports that leader node has already sent data to
+// all nodes it can choose.
+func (n Network) IsQuiescentCentralised() bool {
+	return n.exhausted(0)
+}
+
+// never is a quiescence check of algorithms which propagate forever.
+func never(Network) bool {
+	return false
+}
+
 func (n Network) CountCoverage() (result int) {
 	result = 0
 	for _, v := range n.Topology {
@@ -0,0 +1,113 @@
+package model
+
+/*
+	Newscast (Jelasity, Kowalczyk and van Steen) peer sampling. Every node
+	keeps cache of size items: peer and round when the peer created the
+	item. In a round every node in id order exchanges caches with random
+	peer of its cache: both merge caches and fresh items of each other and
+	keep size freshest items of other nodes. Crashed nodes do not create
+	fresh items, so their items are pushed out of caches, failed exchange
+	drops the crashed peer at once. Caches start as random nodes as in
+	Cyclon. Exchanging nodes end with the same caches, so views are highly
+	clustered and small caches split the network into cliques.
+*/
+
+import "sort"
+
+type (
+	// Newscast keeps caches of items of every node.
+	Newscast struct {
+		net     *Network
+		caches  [][]newsItem
+		size    int
+		round   int
+		crashed map[int]bool
+	}
+
+	newsItem struct {
+		node  int
+		stamp int // round when the item was created
+	}
+)
+
+// NewNewscast creates caches of size random items and runs exchange
+// rounds. Random source of the network is used.
+func NewNewscast(n *Network, size int) *Newscast {
+	s := &Newscast{
+		net:     n,
+		caches:  make([][]newsItem, len(n.Topology)),
+		size:    size,
+		crashed: make(map[int]bool),
+	}
+	for node := range s.caches {
+		for _, peer := range n.ChooseNodesCheck(size, map[int]bool{node: true}) {
+			s.caches[node] = append(s.caches[node], newsItem{node: peer})
+		}
+	}
+	s.rounds(samplerRounds)
+	return s
+}
+
+func (s *Newscast) Sample(node, count int, exclude map[int]bool) []int {
+	return sampleView(s.net, s.View(node), count, exclude)
+}
+
+func (s *Newscast) View(node int) []int {
+	view := make([]int, len(s.caches[node]))
+	for i, item := range s.caches[node] {
+		view[i] = item.node
+	}
+	return view
+}
+
+// Crash stops the nodes and runs exchange rounds, which push their items
+// out of caches.
+func (s *Newscast) Crash(nodes []int) {
+	for _, node := range nodes {
+		s.crashed[node] = true
+	}
+	s.rounds(samplerRounds)
+}
+
+func (s *Newscast) rounds(num int) {
+	for i := 0; i < num; i++ {
+		s.round++
+		for node := range s.caches {
+			if s.crashed[node] || len(s.caches[node]) == 0 {
+				continue
+			}
+			i := s.net.intn(len(s.caches[node]))
+			peer := s.caches[node][i].node
+			if s.crashed[peer] {
+				s.caches[node] = append(s.caches[node][:i], s.caches[node][i+1:]...)
+				continue
+			}
+			merged := append(append([]newsItem{
+				{node: node, stamp: s.round},
+				{node: peer, stamp: s.round},
+			}, s.caches[node]...), s.caches[peer]...)
+			s.caches[node] = s.freshest(node, merged)
+			s.caches[peer] = s.freshest(peer, merged)
+		}
+	}
+}
+
+// freshest returns size freshest items of distinct nodes except the node.
+func (s *Newscast) freshest(node int, items []newsItem) []newsItem {
+	sorted := append([]newsItem(nil), items...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].stamp > sorted[j].stamp
+	})
+	seen := map[int]bool{node: true}
+	var cache []newsItem
+	for _, item := range sorted {
+		if len(cache) == s.size {
+			break
+		}
+		if !seen[item.node] {
+			seen[item.node] = true
+			cache = append(cache, item)
+		}
+	}
+	return cache
+}
@@ -0,0 +1,278 @@
+package model
+
+import "sort"
+
+/*
+	Plumtree (Leitão, Pereira and Rodrigues, epidemic broadcast trees) over
+	a random overlay. Every node links to at least F random peers, links
+	are symmetric and split into eager and lazy ones. Node got data for the
+	first time sends it to eager peers and IHAVE announcement to lazy peers,
+	except peer which delivered the data:
+	`- duplicate data prunes the link: both sides move it to lazy peers
+	`- node which got IHAVE but not data within graft timeout epochs sends
+	   GRAFT to announcer: both sides move the link to eager peers and the
+	   announcer sends data in the next epoch
+	Initially all links are eager, so the first broadcast floods the overlay
+	and prunes it to a spanning tree used by next broadcasts. Crashed nodes
+	do not get messages, sender drops the link with crashed peer as with
+	broken connection, and GRAFT repairs the tree. Optimisation of the tree
+	by IHAVE arrived earlier than data is not modelled.
+*/
+
+// DefaultGraftTimeout is a number of epochs node waits for data after
+// IHAVE before GRAFT. Short timeout grafts shortcuts to the tree which are
+// pruned by the next broadcasts, long timeout delays repair of the tree.
+const DefaultGraftTimeout = 3
+
+type (
+	// Plumtree keeps eager and lazy peers of every node across broadcasts,
+	// states of the last broadcast are kept in the network.
+	Plumtree struct {
+		net     *Network
+		eager   map[int]map[int]bool
+		lazy    map[int]map[int]bool
+		crashed map[int]bool
+		timeout int // graft timeout, see SetGraftTimeout
+	}
+
+	// PlumtreeStat is a message accounting of single broadcast. Stat counts
+	// data messages, messages to crashed peers are counted as Lost only.
+	PlumtreeStat struct {
+		Stat
+		IHave  int `json:"ihave"`  // IHAVE announcements
+		Graft  int `json:"graft"`  // GRAFT requests
+		Prune  int `json:"prune"`  // PRUNE replies to duplicates
+		Lost   int `json:"lost"`   // messages to crashed peers
+		Epochs int `json:"epochs"` // epochs until the last node got data
+	}
+)
+
+// NewPlumtree links every node of the network to at least fanout random
+// peers, all links are eager. Random source of the network is used.
+func NewPlumtree(net *Network, fanout int) *Plumtree {
+	p := &Plumtree{
+		net:     net,
+		eager:   make(map[int]map[int]bool, len(net.Topology)),
+		lazy:    make(map[int]map[int]bool, len(net.Topology)),
+		crashed: make(map[int]bool),
+		timeout: DefaultGraftTimeout,
+	}
+	for node := range net.Topology {
+		p.eager[node] = make(map[int]bool)
+		p.lazy[node] = make(map[int]bool)
+	}
+	for node := 0; node < len(net.Topology); node++ {
+		if len(p.eager[node]) >= fanout {
+			continue
+		}
+		exclude := map[int]bool{node: true}
+		for peer := range p.eager[node] {
+			exclude[peer] = true
+		}
+		for _, peer := range net.ChooseNodesCheck(fanout-len(p.eager[node]), exclude) {
+			p.eager[node][peer] = true
+			p.eager[peer][node] = true
+		}
+	}
+	return p
+}
+
+// SetGraftTimeout sets number of epochs node waits for data after IHAVE,
+// see DefaultGraftTimeout.
+func (p *Plumtree) SetGraftTimeout(epochs int) {
+	p.timeout = epochs
+}
+
+// Network returns network with states of the last broadcast.
+func (p *Plumtree) Network() *Network {
+	return p.net
+}
+
+// Crash stops the node: it does not get or send messages anymore.
+func (p *Plumtree) Crash(node int) {
+	p.crashed[node] = true
+}
+
+// CrashRandom crashes count random alive nodes and returns them.
+func (p *Plumtree) CrashRandom(count int) []int {
+	var nodes []int
+	for len(nodes) < count && len(p.crashed) < len(p.net.Topology) {
+		node := p.RandomAlive()
+		p.Crash(node)
+		nodes = append(nodes, node)
+	}
+	return nodes
+}
+
+// RandomAlive returns random node which did not crash. There must be such
+// a node.
+func (p *Plumtree) RandomAlive() int {
+	for {
+		node := p.net.intn(len(p.net.Topology))
+		if !p.crashed[node] {
+			return node
+		}
+	}
+}
+
+// Alive returns number of nodes which did not crash.
+func (p *Plumtree) Alive() int {
+	return len(p.net.Topology) - len(p.crashed)
+}
+
+// EagerLinks returns number of eager links, it is Alive()-1 for spanning
+// tree of connected overlay.
+func (p *Plumtree) EagerLinks() int {
+	links := 0
+	for node, peers := range p.eager {
+		for peer := range peers {
+			if node < peer && !p.crashed[node] && !p.crashed[peer] {
+				links++
+			}
+		}
+	}
+	return links
+}
+
+// Broadcast disseminates new data from the source until no messages are
+// in flight. Network states and history are reset to the broadcast ones.
+func (p *Plumtree) Broadcast(source int) PlumtreeStat {
+	n := p.net
+	for node := range n.Topology {
+		n.Topology[node] = 0
+	}
+	n.History = make(map[int]map[int][]int)
+	n.hops = nil
+	n.Topology[source] = 1
+
+	var s PlumtreeStat
+	parent := map[int]int{source: source}
+	announced := make(map[int][]int) // announcers by node without data
+	waiting := make(map[int]int)     // epoch of the last IHAVE or GRAFT by node without data
+	grafted := make(map[int][]int)   // nodes to send data to by announcer
+
+	for epoch := 0; p.busy(grafted, waiting); epoch++ {
+		data := make(map[int][]int) // senders by receiver
+		ihave := make(map[int][]int)
+		for ind := 0; ind < len(n.Topology); ind++ {
+			var sent []int
+			if n.Topology[ind] == 1 {
+				n.Topology[ind] = -1
+				for _, peer := range sortedPeers(p.eager[ind]) {
+					if peer != parent[ind] && p.reachable(ind, peer, &s) {
+						sent = append(sent, peer)
+					}
+				}
+				for _, peer := range sortedPeers(p.lazy[ind]) {
+					if peer != parent[ind] && p.reachable(ind, peer, &s) {
+						ihave[peer] = append(ihave[peer], ind)
+						s.IHave++
+					}
+				}
+			}
+			sent = append(sent, grafted[ind]...)
+			delete(grafted, ind)
+			if len(sent) == 0 {
+				continue
+			}
+			n.SetHistoryEpoch(ind, epoch, sent)
+			s.Sent += len(sent)
+			for _, node := range sent {
+				data[node] = append(data[node], ind)
+			}
+		}
+
+		for node := 0; node < len(n.Topology); node++ {
+			senders := data[node]
+			if len(senders) == 0 {
+				continue
+			}
+			s.account(n.Topology[node] != 0, len(senders))
+			if n.Topology[node] == 0 {
+				n.Topology[node] = 1
+				parent[node] = senders[0]
+				p.link(p.eager, node, senders[0])
+				senders = senders[1:]
+				delete(announced, node)
+				delete(waiting, node)
+				s.Epochs = epoch + 1
+			}
+			for _, sender := range senders {
+				p.link(p.lazy, node, sender)
+				s.Prune++
+			}
+		}
+
+		for node := 0; node < len(n.Topology); node++ {
+			if len(ihave[node]) == 0 || n.Topology[node] != 0 {
+				continue
+			}
+			if len(announced[node]) == 0 {
+				waiting[node] = epoch
+			}
+			announced[node] = append(announced[node], ihave[node]...)
+		}
+		for node := 0; node < len(n.Topology); node++ {
+			since, ok := waiting[node]
+			if !ok || epoch-since < p.timeout {
+				continue
+			}
+			if len(announced[node]) == 0 {
+				delete(waiting, node)
+				continue
+			}
+			announcer := announced[node][0]
+			announced[node] = announced[node][1:]
+			waiting[node] = epoch + 1 // data arrives in the next epoch
+			p.link(p.eager, node, announcer)
+			grafted[announcer] = append(grafted[announcer], node)
+			s.Graft++
+		}
+	}
+	s.Coverage = n.CountCoverage()
+	return s
+}
+
+// busy reports that some messages are still in flight.
+func (p *Plumtree) busy(grafted map[int][]int, waiting map[int]int) bool {
+	if len(grafted) > 0 || len(waiting) > 0 {
+		return true
+	}
+	for _, v := range p.net.Topology {
+		if v == 1 {
+			return true
+		}
+	}
+	return false
+}
+
+// reachable reports that peer did not crash, otherwise the link is dropped
+// and the message is counted as lost.
+func (p *Plumtree) reachable(node, peer int, s *PlumtreeStat) bool {
+	if !p.crashed[peer] {
+		return true
+	}
+	delete(p.eager[node], peer)
+	delete(p.lazy[node], peer)
+	s.Lost++
+	return false
+}
+
+// link moves link between nodes to the given peers of both sides.
+func (p *Plumtree) link(peers map[int]map[int]bool, a, b int) {
+	for _, m := range []map[int]map[int]bool{p.eager, p.lazy} {
+		delete(m[a], b)
+		delete(m[b], a)
+	}
+	peers[a][b] = true
+	peers[b][a] = true
+}
+
+func sortedPeers(peers map[int]bool) []int {
+	nodes := make([]int, 0, len(peers))
+	for node := range peers {
+		nodes = append(nodes, node)
+	}
+	sort.Ints(nodes)
+	return nodes
+}
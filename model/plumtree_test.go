@@ -0,0 +1,48 @@
+package model
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlumtree_Broadcast(t *testing.T) {
+	net, err := SampleNetwork(200)
+	require.NoError(t, err)
+	net.SetRandSource(rand.NewSource(1))
+	tree := NewPlumtree(&net, 4)
+	for node, peers := range tree.eager {
+		require.True(t, len(peers) >= 4)
+		for peer := range peers {
+			require.True(t, tree.eager[peer][node])
+		}
+	}
+
+	// the first broadcast floods the overlay and prunes it to a tree
+	s := tree.Broadcast(0)
+	require.Equal(t, 200, s.Coverage)
+	require.Equal(t, 199, s.Delivered)
+	require.Equal(t, s.Delivered+s.Stale+s.Concurrent, s.Sent)
+	require.Equal(t, s.Reused, s.Prune)
+	require.Equal(t, 0, s.Graft)
+	require.Equal(t, 199, tree.EagerLinks())
+
+	// the tree delivers data with a message per node
+	tree.SetGraftTimeout(200)
+	s = tree.Broadcast(tree.RandomAlive())
+	require.Equal(t, 200, s.Coverage)
+	require.Equal(t, 199, s.Sent)
+	require.Equal(t, 0, s.Reused)
+	require.True(t, s.IHave > 0)
+
+	// GRAFT repairs the tree after crashes
+	tree.SetGraftTimeout(DefaultGraftTimeout)
+	require.Len(t, tree.CrashRandom(20), 20)
+	require.Equal(t, 180, tree.Alive())
+	s = tree.Broadcast(tree.RandomAlive())
+	require.Equal(t, 180, s.Coverage)
+	require.True(t, s.Lost > 0)
+	require.True(t, s.Graft > 0)
+	require.Equal(t, s.Delivered+s.Stale+s.Concurrent, s.Sent)
+}
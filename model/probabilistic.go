@@ -0,0 +1,165 @@
+package model
+
+/*
+	Probabilistic forwarding (Haas, Halpern, Li "Gossip-based ad hoc
+	routing"). Neighbors of a node are alive nodes of its membership view,
+	every other node of the full mesh with global membership. Sender
+	forwards to F random neighbors chosen as in RunEpochNaiveOnce:
+	`- gossip1     : GOSSIP1(p,k), node forwards to all F nodes with
+	                 probability p, nodes of the first k hops always forward
+	`- gossip2     : GOSSIP2(p,k,p2,n), GOSSIP1(p,k) where nodes with less
+	                 than n neighbors forward with probability p2, so
+	                 propagation does not die out at sparse parts of views
+	`- gossip-edge : node forwards to each of F nodes with probability p,
+	                 first k hops forward to all of them
+	Dropped messages are not sent, so they are not counted in Stat.
+	With global membership and views of fixed size every node has the
+	same number of neighbors, degrees of partial views differ with
+	crashes and with views of hyparview and newscast.
+*/
+
+// SetForwarding sets probability p of forwarding and number of the first
+// hops which always forward for probabilistic algorithms. Zero p stands
+// for 1, i.e. every message is forwarded.
+func (n *Network) SetForwarding(p float64, hops int) {
+	n.forward = p
+	n.forwardHops = hops
+}
+
+// SetLowDegreeForwarding sets probability p2 of forwarding of nodes with
+// less than degree neighbors for GOSSIP2. Zero p2 stands for 1.
+func (n *Network) SetLowDegreeForwarding(p2 float64, degree int) {
+	n.lowForward = p2
+	n.lowDegree = degree
+}
+
+// forwards reports that sender of the epoch forwards a message.
+func (n *Network) forwards(epoch int) bool {
+	if epoch < n.forwardHops || n.forward == 0 {
+		return true
+	}
+	return n.random().Float64() < n.forward
+}
+
+// forwardsByDegree reports that sender of the epoch forwards a message
+// with probability of its number of neighbors.
+func (n *Network) forwardsByDegree(sender, epoch int) bool {
+	if epoch < n.forwardHops || n.degree(sender) >= n.lowDegree {
+		return n.forwards(epoch)
+	}
+	if n.lowForward == 0 {
+		return true
+	}
+	return n.random().Float64() < n.lowForward
+}
+
+// degree returns number of alive nodes of the node view.
+func (n *Network) degree(node int) int {
+	view := n.PeerSampler().View(node)
+	if view == nil {
+		return len(n.Topology) - 1 - len(n.down)
+	}
+	degree := 0
+	for _, peer := range view {
+		if !n.down[peer] {
+			degree++
+		}
+	}
+	return degree
+}
+
+// With probability p choose F other nodes and propagate info, otherwise
+// drop it. Do it once in lifetime.
+// Topology notation is the same as in RunEpochNaiveOnce.
+func (n *Network) RunEpochGossip1(fanout int, epoch int) Stat {
+	return n.runEpochGossip(fanout, epoch, func(int) bool {
+		return n.forwards(epoch)
+	})
+}
+
+// GOSSIP1 where nodes with less than n neighbors forward with probability
+// p2. Do it once in lifetime.
+// Topology notation is the same as in RunEpochNaiveOnce.
+func (n *Network) RunEpochGossip2(fanout int, epoch int) Stat {
+	return n.runEpochGossip(fanout, epoch, func(node int) bool {
+		return n.forwardsByDegree(node, epoch)
+	})
+}
+
+// runEpochGossip is RunEpochNaiveOnce where sender drops data unless
+// forwards reports it forwards.
+func (n *Network) runEpochGossip(fanout int, epoch int, forwards func(node int) bool) Stat {
+	var s Stat
+
+	newVotes := make(map[int]int, len(n.Topology))
+
+	for ind := 0; ind < len(n.Topology); ind++ {
+		if n.Topology[ind] == 1 {
+			if n.expired(ind) {
+				n.Topology[ind] = -1
+				continue
+			}
+			n.Topology[ind] = -1
+			if !forwards(ind) {
+				continue
+			}
+			voted := n.choose(ind, fanout)
+			n.SetHistoryEpoch(ind, epoch, voted)
+			s.Sent += len(voted)
+			s.Excluded += fanout - len(voted)
+			for _, vote := range voted {
+				newVotes[vote]++
+			}
+		}
+	}
+
+	for node, repeated := range newVotes {
+		s.account(n.Topology[node] != 0, repeated)
+		if n.Topology[node] == 0 {
+			n.Topology[node] = 1
+		}
+	}
+	s.Coverage = n.CountCoverage()
+	return s
+}
+
+// Choose F other nodes and propagate info to each of them with
+// probability p. Do it once in lifetime.
+// Topology notation is the same as in RunEpochNaiveOnce.
+func (n *Network) RunEpochGossipEdge(fanout int, epoch int) Stat {
+	var s Stat
+
+	newVotes := make(map[int]int, len(n.Topology))
+
+	for ind := 0; ind < len(n.Topology); ind++ {
+		if n.Topology[ind] == 1 {
+			if n.expired(ind) {
+				n.Topology[ind] = -1
+				continue
+			}
+			chosen := n.choose(ind, fanout)
+			s.Excluded += fanout - len(chosen)
+			voted := make([]int, 0, len(chosen))
+			for _, node := range chosen {
+				if n.forwards(epoch) {
+					voted = append(voted, node)
+				}
+			}
+			n.SetHistoryEpoch(ind, epoch, voted)
+			s.Sent += len(voted)
+			for _, vote := range voted {
+				newVotes[vote]++
+			}
+			n.Topology[ind] = -1
+		}
+	}
+
+	for node, repeated := range newVotes {
+		s.account(n.Topology[node] != 0, repeated)
+		if n.Topology[node] == 0 {
+			n.Topology[node] = 1
+		}
+	}
+	s.Coverage = n.CountCoverage()
+	return s
+}
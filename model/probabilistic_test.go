@@ -0,0 +1,91 @@
+package model
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNetwork_RunEpochGossip(t *testing.T) {
+	// zero probability stands for 1, so it is naive-once
+	net, err := prepareNetwork(100)
+	require.NoError(t, err)
+	require.Equal(t, 5, net.RunEpochGossip1(5, 0).Sent)
+
+	// initial node always forwards in the first hop
+	net, err = prepareNetwork(100)
+	require.NoError(t, err)
+	net.SetRandSource(rand.NewSource(1))
+	net.SetForwarding(0.01, 1)
+	require.Equal(t, 5, net.RunEpochGossipEdge(5, 0).Sent)
+
+	// node forwards to all or nothing
+	net, err = prepareNetwork(1000)
+	require.NoError(t, err)
+	net.SetRandSource(rand.NewSource(1))
+	net.SetForwarding(0.5, 0)
+	forwarded := 0
+	for i := 0; i < 1000; i++ {
+		net.Topology[i] = 1
+	}
+	s := net.RunEpochGossip1(3, 0)
+	for _, voted := range net.History[0] {
+		require.Len(t, voted, 3)
+		forwarded++
+	}
+	require.Equal(t, 3*forwarded, s.Sent)
+	require.InDelta(t, 500, forwarded, 60)
+
+	// node forwards to each chosen node independently
+	net, err = prepareNetwork(1000)
+	require.NoError(t, err)
+	net.SetRandSource(rand.NewSource(1))
+	net.SetForwarding(0.5, 0)
+	for i := 0; i < 1000; i++ {
+		net.Topology[i] = 1
+	}
+	s = net.RunEpochGossipEdge(4, 0)
+	require.InDelta(t, 2000, s.Sent, 150)
+	require.Equal(t, 0, s.Excluded)
+}
+
+func TestNetwork_RunEpochGossip2(t *testing.T) {
+	// with global membership every node has enough neighbors, so it is gossip1
+	stats := make([]Stat, 2)
+	for i, alg := range []Algorithm{(*Network).RunEpochGossip1, (*Network).RunEpochGossip2} {
+		net, err := prepareNetwork(100)
+		require.NoError(t, err)
+		net.SetRandSource(rand.NewSource(1))
+		net.SetForwarding(0.5, 1)
+		net.SetLowDegreeForwarding(0, 10)
+		for epoch := 0; !net.IsQuiescentOnce(); epoch++ {
+			stats[i].Add(alg(&net, 3, epoch))
+		}
+	}
+	require.Equal(t, stats[0], stats[1])
+
+	// only nodes which lost neighbors by crashes forward
+	net, err := prepareNetwork(1000)
+	require.NoError(t, err)
+	net.SetRandSource(rand.NewSource(1))
+	net.SetPeerSampler(NewStatic(&net, 5))
+	net.CrashRandomNodes(200, 0)
+	net.SetForwarding(1e-9, 0)
+	net.SetLowDegreeForwarding(0, 5)
+	sparse := 0
+	for node := range net.Topology {
+		if !net.down[node] {
+			net.Topology[node] = 1
+			if net.degree(node) < 5 {
+				sparse++
+			}
+		}
+	}
+	net.RunEpochGossip2(3, 0)
+	require.Len(t, net.History[0], sparse)
+	for node := range net.History[0] {
+		require.True(t, net.degree(node) < 5)
+	}
+	require.True(t, sparse > 100)
+}
@@ -0,0 +1,57 @@
+package model
+
+/*
+	Push-pull gossip: every alive node chooses F other nodes each epoch.
+	Node with data pushes it to chosen nodes, node without data pulls it:
+	chosen nodes which had data at the epoch start reply with it. Nodes
+	which got data within TTL keep pushing and replying forever, so
+	propagation ends when network is filled or when all nodes with data
+	expired, see IsQuiescentOnce. Only messages with data are counted,
+	pull requests and replies without data are not.
+*/
+
+// Node with data pushes it to F other nodes, node without data pulls it
+// from F other nodes. Do it forever until some service will stop it.
+// Topology notation is the same as in RunEpochNaiveForever.
+func (n *Network) RunEpochPushPull(fanout int, epoch int) Stat {
+	var s Stat
+
+	newVotes := make(map[int]int, len(n.Topology))
+	sent := make([][]int, len(n.Topology))
+	pushed := make([]bool, len(n.Topology))
+
+	for ind := 0; ind < len(n.Topology); ind++ {
+		switch {
+		case n.down[ind] || n.expired(ind):
+			continue
+		case n.Topology[ind] != 0:
+			voted := n.choose(ind, fanout)
+			s.Excluded += fanout - len(voted)
+			sent[ind] = append(sent[ind], voted...)
+			pushed[ind] = true
+		default:
+			for _, peer := range n.choose(ind, fanout) {
+				if n.Topology[peer] != 0 && !n.expired(peer) {
+					sent[peer] = append(sent[peer], ind)
+				}
+			}
+		}
+	}
+
+	for ind, voted := range sent {
+		if len(voted) == 0 && !pushed[ind] {
+			continue
+		}
+		n.SetHistoryEpoch(ind, epoch, voted)
+		s.Sent += len(voted)
+		for _, vote := range voted {
+			newVotes[vote]++
+		}
+	}
+	for node, repeated := range newVotes {
+		s.account(n.Topology[node] != 0, repeated)
+		n.Topology[node] = 1
+	}
+	s.Coverage = n.CountCoverage()
+	return s
+}
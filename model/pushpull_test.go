@@ -0,0 +1,31 @@
+package model
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNetwork_RunEpochPushPull(t *testing.T) {
+	// node 0 pulls data from node 1, which pushes it to node 0 as well
+	net, err := SampleNetwork(2)
+	require.NoError(t, err)
+	require.NoError(t, net.VisitNode(1))
+	require.Equal(t, Stat{Sent: 2, Coverage: 2, Reused: 1, Delivered: 1, Concurrent: 1}, net.RunEpochPushPull(1, 0))
+	require.Equal(t, []int{0, 0}, net.GetHistoryEpoch(1, 0))
+
+	// pull finishes propagation faster than push alone
+	epochs := func(alg Algorithm) int {
+		net, err := prepareNetwork(1000)
+		require.NoError(t, err)
+		net.SetRandSource(rand.NewSource(1))
+		epoch := 0
+		for ; !net.IsNetworkFilled(); epoch++ {
+			alg(&net, 1, epoch)
+		}
+		return epoch
+	}
+	pushPull, push := epochs((*Network).RunEpochPushPull), epochs((*Network).RunEpochNaiveForever)
+	require.True(t, pushPull < push, "push-pull %d, push %d", pushPull, push)
+}
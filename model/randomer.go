@@ -10,10 +10,13 @@ type (
 	CryptoSource struct {
 		buf [8]byte
 	}
-)
 
-var (
-	r = mrand.New(&CryptoSource{})
+	// AntitheticSource mirrors values of the wrapped source, so uniform
+	// value u of the wrapped source becomes 1-u. Networks with seeded
+	// source and its antithetic pair produce negatively correlated choices.
+	AntitheticSource struct {
+		Source mrand.Source
+	}
 )
 
 func (s *CryptoSource) Int63() int64 {
@@ -26,14 +29,61 @@ func (s CryptoSource) Seed(seed int64) {
 
 }
 
+func (s AntitheticSource) Int63() int64 {
+	return 1<<63 - 1 - s.Source.Int63()
+}
+
+func (s AntitheticSource) Seed(seed int64) {
+	s.Source.Seed(seed)
+}
+
+// SetRandSource makes network use the random source instead of crypto
+// source, so propagation can be reproduced with seeded source.
+func (n *Network) SetRandSource(src mrand.Source) {
+	n.rand = mrand.New(src)
+}
+
+// random returns random source of the network. Network without source
+// gets its own crypto source: mrand.Rand is not safe for concurrent use
+// and networks of concurrent experiments must not share it.
+func (n *Network) random() *mrand.Rand {
+	if n.rand == nil {
+		n.rand = mrand.New(&CryptoSource{})
+	}
+	return n.rand
+}
+
+// intn returns random number in [0, max) as a monotone function of uniform
+// value, so antithetic source gives mirrored numbers.
+func (n *Network) intn(max int) int {
+	v := int(n.random().Float64() * float64(max))
+	if v >= max { // float rounding
+		v = max - 1
+	}
+	return v
+}
+
+// perm returns random permutation of [0, size) with Fisher-Yates shuffle
+// based on intn.
+func (n *Network) perm(size int) []int {
+	p := make([]int, size)
+	for i := range p {
+		j := n.intn(i + 1)
+		p[i] = p[j]
+		p[j] = i
+	}
+	return p
+}
+
 func (n *Network) ChooseNodesCheck(fanout int, exclude map[int]bool) []int {
 	if fanout > len(n.Topology) {
 		return []int{}
 	}
 	var nodes []int
-	if len(n.Topology)-len(exclude) <= len(n.Topology)/2 {
-		// if re-random is way too long
-		candidates := r.Perm(len(n.Topology))
+	available := len(n.Topology) - len(exclude)
+	if available <= len(n.Topology)/2 || fanout >= available {
+		// if re-random is way too long or never ends
+		candidates := n.perm(len(n.Topology))
 		for i := 0; len(nodes) < fanout && i < len(n.Topology); i++ {
 			if !exclude[candidates[i]] {
 				nodes = append(nodes, candidates[i])
@@ -43,7 +93,7 @@ func (n *Network) ChooseNodesCheck(fanout int, exclude map[int]bool) []int {
 		// if re-random is fast
 		alreadySelected := make(map[int]bool, fanout)
 		for len(nodes) < fanout {
-			candidate := r.Intn(len(n.Topology))
+			candidate := n.intn(len(n.Topology))
 			if !exclude[candidate] && !alreadySelected[candidate] {
 				nodes = append(nodes, candidate)
 				alreadySelected[candidate] = true
@@ -0,0 +1,283 @@
+package model
+
+/*
+	Reconciliation of replicated state. Every node owns keys, updates them
+	and replicates keys of all nodes: entry of the node is a version of key
+	of participant it knows. Participant increments its version counter on
+	every update, so the maximum version of participant known by the node
+	describes its entries. In an epoch nodes update random own keys with
+	update rate probability, then every node in id order gossips with F
+	random peers of its membership by the protocol. As with propagation,
+	node forwards in the next epoch what it got, so peers send entries of
+	the epoch start:
+	`- scuttlebutt  : push-pull of van Renesse et al., peers exchange
+	   digests of maximum versions of all participants and send deltas
+	   newer than digest of other side; participants with more deltas go
+	   first (scuttle-depth), deltas of participant in version order, at
+	   most MTU deltas in each direction
+	`- anti-entropy : push-pull of whole state, peers keep newer versions
+	`- push         : node pushes deltas it created or learned in the
+	   previous epoch (rumors), at most MTU of them, lost rumors are never
+	   repaired
+	Staleness is a share of entries older than versions of their owners.
+*/
+
+import (
+	"errors"
+	"sort"
+)
+
+const (
+	ScuttlebuttProtocol = "scuttlebutt"
+	AntiEntropyProtocol = "anti-entropy"
+	PushProtocol        = "push"
+)
+
+const (
+	DefaultKeys = 4  // keys of every node
+	DefaultMTU  = 16 // deltas in each direction of exchange
+
+	DeltaBytes  = 32 // participant, key, version and value of delta
+	DigestBytes = 8  // participant and maximum version of digest entry
+)
+
+type (
+	// Reconciliation keeps replicated state of every node of the network.
+	Reconciliation struct {
+		net       *Network
+		exchange  func(r *Reconciliation, a, b int, s *ReconcileStat)
+		keys      int
+		mtu       int
+		rate      float64
+		versions  [][]int // entries of node by participant*keys+key
+		maxima    [][]int // maximum versions of participants known by node
+		sent      [][]int // entries of the epoch start
+		digests   [][]int // maximum versions of the epoch start
+		rumored   bool    // push protocol keeps rumors
+		rumors    [][]delta
+		nextRumor [][]delta
+	}
+
+	delta struct {
+		participant int
+		key         int
+		version     int
+	}
+
+	// ReconcileStat is an accounting of reconciliation epoch. Stat counts
+	// deltas as messages: Stale ones were not newer than entries of
+	// receivers at the epoch start, Concurrent ones brought versions
+	// received earlier in the epoch. Coverage is a number of nodes with
+	// all entries up to date.
+	ReconcileStat struct {
+		Stat
+		Exchanges int     `json:"exchanges"` // gossip exchanges of pairs of nodes
+		Bytes     int     `json:"bytes"`     // bytes of digests and deltas
+		Staleness float64 `json:"staleness"` // share of entries behind owners
+	}
+)
+
+var (
+	ErrUnknownProtocol = errors.New("unknown reconciliation protocol")
+
+	protocols = map[string]func(r *Reconciliation, a, b int, s *ReconcileStat){
+		ScuttlebuttProtocol: (*Reconciliation).scuttlebutt,
+		AntiEntropyProtocol: (*Reconciliation).antiEntropy,
+		PushProtocol:        (*Reconciliation).push,
+	}
+)
+
+// NewReconciliation creates state of keys of every node of the network,
+// all entries are at version 0. MTU limits deltas of scuttlebutt and push
+// exchanges. Random source of the network is used.
+func NewReconciliation(n *Network, protocol string, keys, mtu int, rate float64) (*Reconciliation, error) {
+	exchange, ok := protocols[protocol]
+	if !ok {
+		return nil, ErrUnknownProtocol
+	}
+	size := len(n.Topology)
+	r := &Reconciliation{
+		net:       n,
+		exchange:  exchange,
+		keys:      keys,
+		mtu:       mtu,
+		rate:      rate,
+		rumored:   protocol == PushProtocol,
+		versions:  make([][]int, size),
+		maxima:    make([][]int, size),
+		sent:      make([][]int, size),
+		digests:   make([][]int, size),
+		rumors:    make([][]delta, size),
+		nextRumor: make([][]delta, size),
+	}
+	for node := range r.versions {
+		r.versions[node] = make([]int, size*keys)
+		r.maxima[node] = make([]int, size)
+		r.sent[node] = make([]int, size*keys)
+		r.digests[node] = make([]int, size)
+	}
+	return r, nil
+}
+
+// ProtocolNames returns sorted names of reconciliation protocols.
+func ProtocolNames() []string {
+	names := make([]string, 0, len(protocols))
+	for name := range protocols {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Epoch updates keys when update is set and runs exchanges of every node
+// with fanout random peers.
+func (r *Reconciliation) Epoch(fanout int, update bool) ReconcileStat {
+	var s ReconcileStat
+	r.rumors, r.nextRumor = r.nextRumor, make([][]delta, len(r.versions))
+	if update {
+		for node := range r.versions {
+			if r.net.random().Float64() < r.rate {
+				r.update(node, r.net.intn(r.keys))
+			}
+		}
+	}
+	for node := range r.versions {
+		copy(r.sent[node], r.versions[node])
+		copy(r.digests[node], r.maxima[node])
+	}
+	for node := range r.versions {
+		for _, peer := range r.net.PeerSampler().Sample(node, fanout, map[int]bool{node: true}) {
+			r.exchange(r, node, peer, &s)
+			s.Exchanges++
+		}
+	}
+	s.Staleness, s.Coverage = r.staleness()
+	return s
+}
+
+// update sets the next version of participant to its key.
+func (r *Reconciliation) update(node, key int) {
+	r.maxima[node][node]++
+	d := delta{participant: node, key: key, version: r.maxima[node][node]}
+	r.versions[node][node*r.keys+key] = d.version
+	r.rumors[node] = append(r.rumors[node], d)
+}
+
+// scuttlebutt exchanges digests and deltas newer than digests of peers.
+func (r *Reconciliation) scuttlebutt(a, b int, s *ReconcileStat) {
+	toA, toB := r.newer(b, a), r.newer(a, b)
+	s.Bytes += 2*len(r.versions)*DigestBytes + (len(toA)+len(toB))*DeltaBytes
+	r.apply(a, toA, s)
+	r.apply(b, toB, s)
+}
+
+// newer returns at most MTU deltas of the node newer than digest of the
+// peer, participants with more deltas first.
+func (r *Reconciliation) newer(node, peer int) []delta {
+	var parts [][]delta
+	for p, version := range r.digests[node] {
+		if version <= r.digests[peer][p] {
+			continue
+		}
+		var part []delta
+		for key := 0; key < r.keys; key++ {
+			if v := r.sent[node][p*r.keys+key]; v > r.digests[peer][p] {
+				part = append(part, delta{participant: p, key: key, version: v})
+			}
+		}
+		sort.Slice(part, func(i, j int) bool { return part[i].version < part[j].version })
+		parts = append(parts, part)
+	}
+	sort.SliceStable(parts, func(i, j int) bool { return len(parts[i]) > len(parts[j]) })
+
+	var deltas []delta
+	for _, part := range parts {
+		if len(deltas)+len(part) > r.mtu {
+			part = part[:r.mtu-len(deltas)]
+		}
+		deltas = append(deltas, part...)
+		if len(deltas) == r.mtu {
+			break
+		}
+	}
+	return deltas
+}
+
+// antiEntropy exchanges whole states of peers.
+func (r *Reconciliation) antiEntropy(a, b int, s *ReconcileStat) {
+	toA, toB := r.state(b), r.state(a)
+	s.Bytes += (len(toA) + len(toB)) * DeltaBytes
+	r.apply(a, toA, s)
+	r.apply(b, toB, s)
+}
+
+// state returns all known entries of the node.
+func (r *Reconciliation) state(node int) []delta {
+	var deltas []delta
+	for i, v := range r.sent[node] {
+		if v > 0 {
+			deltas = append(deltas, delta{participant: i / r.keys, key: i % r.keys, version: v})
+		}
+	}
+	return deltas
+}
+
+// push sends at most MTU rumors of the node to the peer.
+func (r *Reconciliation) push(a, b int, s *ReconcileStat) {
+	deltas := r.rumors[a]
+	if len(deltas) > r.mtu {
+		deltas = deltas[:r.mtu]
+	}
+	s.Bytes += len(deltas) * DeltaBytes
+	r.apply(b, deltas, s)
+}
+
+// apply merges deltas into entries of the node, new deltas become its
+// rumors of the next epoch with push protocol.
+func (r *Reconciliation) apply(node int, deltas []delta, s *ReconcileStat) {
+	for _, d := range deltas {
+		i := d.participant*r.keys + d.key
+		switch {
+		case d.version <= r.sent[node][i]:
+			s.Stale++
+			s.Reused++
+			continue
+		case d.version <= r.versions[node][i]:
+			s.Concurrent++
+			s.Reused++
+			continue
+		}
+		s.Delivered++
+		r.versions[node][i] = d.version
+		if d.version > r.maxima[node][d.participant] {
+			r.maxima[node][d.participant] = d.version
+		}
+		if r.rumored {
+			r.nextRumor[node] = append(r.nextRumor[node], d)
+		}
+	}
+	s.Sent += len(deltas)
+}
+
+// staleness returns share of entries older than versions of owners and
+// number of nodes with all entries up to date.
+func (r *Reconciliation) staleness() (float64, int) {
+	if len(r.versions) < 2 {
+		return 0, len(r.versions)
+	}
+	stale, fresh := 0, 0
+	for node, entries := range r.versions {
+		behind := 0
+		for i, v := range entries {
+			p := i / r.keys
+			if p != node && v < r.versions[p][i] {
+				behind++
+			}
+		}
+		if behind == 0 {
+			fresh++
+		}
+		stale += behind
+	}
+	return float64(stale) / float64(len(r.versions)*(len(r.versions)-1)*r.keys), fresh
+}
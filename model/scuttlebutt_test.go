@@ -0,0 +1,56 @@
+package model
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReconciliation(t *testing.T) {
+	_, err := NewReconciliation(&Network{}, "merkle", DefaultKeys, DefaultMTU, 0.5)
+	require.Equal(t, ErrUnknownProtocol, err)
+
+	run := func(protocol string, mtu int) (ReconcileStat, *Reconciliation) {
+		net, err := prepareNetwork(50)
+		require.NoError(t, err)
+		net.SetRandSource(rand.NewSource(1))
+		r, err := NewReconciliation(&net, protocol, DefaultKeys, mtu, 0.5)
+		require.NoError(t, err)
+		var total, s ReconcileStat
+		for epoch := 0; epoch < 30; epoch++ {
+			s = r.Epoch(2, epoch < 10)
+			total.Add(s.Stat)
+			total.Bytes += s.Bytes
+			total.Exchanges += s.Exchanges
+		}
+		require.Equal(t, total.Delivered+total.Stale+total.Concurrent, total.Sent, protocol)
+		require.Equal(t, 30*50*2, total.Exchanges, protocol)
+		total.Staleness, total.Coverage = s.Staleness, s.Coverage
+		return total, r
+	}
+
+	// push-pull protocols converge after updates stop, scuttlebutt sends
+	// only deltas newer than digests
+	anti, _ := run(AntiEntropyProtocol, DefaultMTU)
+	scuttle, r := run(ScuttlebuttProtocol, DefaultMTU)
+	for _, s := range []ReconcileStat{anti, scuttle} {
+		require.Equal(t, 0.0, s.Staleness)
+		require.Equal(t, 50, s.Coverage)
+	}
+	require.Equal(t, 0, scuttle.Stale)
+	require.True(t, scuttle.Bytes < anti.Bytes/2)
+	for node := range r.versions {
+		require.Equal(t, r.versions[0], r.versions[node])
+	}
+
+	// MTU limits deltas of exchange
+	limited, _ := run(ScuttlebuttProtocol, 1)
+	require.True(t, limited.Sent <= 30*50*2*2)
+	require.True(t, limited.Staleness > 0)
+
+	// push does not repair lost rumors
+	push, _ := run(PushProtocol, 1)
+	require.True(t, push.Staleness > 0)
+	require.True(t, push.Sent <= 30*50*2)
+}
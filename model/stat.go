@@ -2,11 +2,27 @@ package model
 
 import "sync"
 
+/*
+	Message accounting of an epoch. Every sender chooses up to fan-out nodes
+	except excluded ones (itself and nodes memorised by the algorithm), and
+	every sent message is classified by its receiver:
+	`- Delivered  : the first message which brought data to the node
+	`- Stale      : message to the node which had data before the epoch
+	`- Concurrent : extra copy to the node which got data in the same epoch
+	so Sent = Delivered + Stale + Concurrent and Reused = Stale + Concurrent.
+	Fan-out slots left empty because all remaining nodes are excluded are
+	counted as Excluded, so Sent + Excluded = senders * fan-out.
+*/
+
 type (
 	Stat struct {
-		Sent     int // Number of sent messages in epoch
-		Coverage int // Proportion of used nodes
-		Reused   int // Number of redundant sent messages
+		Sent       int `json:"sent"`       // Number of sent messages in epoch
+		Coverage   int `json:"coverage"`   // Proportion of used nodes
+		Reused     int `json:"reused"`     // Number of redundant sent messages
+		Delivered  int `json:"delivered"`  // Messages delivered data to nodes without it
+		Stale      int `json:"stale"`      // Messages to nodes had data before the epoch
+		Concurrent int `json:"concurrent"` // Extra copies to nodes got data in the epoch
+		Excluded   int `json:"excluded"`   // Fan-out slots without candidates
 	}
 
 	EpochCounter struct {
@@ -14,9 +30,71 @@ type (
 		Counter    map[int]int
 		ReCounter  int
 		InfCounter int
+
+		// StallCounter is a number of not filled experiments which stopped
+		// propagation before epoch limit, StallCoverage is a sum of their
+		// final coverage.
+		StallCounter  int
+		StallCoverage int
+
+		// MissCounter is a number of experiments by number of nodes
+		// without data at the end of experiment.
+		MissCounter map[int]int
+
+		// SentLoad, ReceivedLoad and DuplicateLoad are numbers of nodes
+		// by messages they sent, received and received as duplicates in
+		// an experiment, over all experiments.
+		SentLoad      map[int]int
+		ReceivedLoad  map[int]int
+		DuplicateLoad map[int]int
+
+		// LatencyCounter is a number of nodes by hop when they got data,
+		// LastCounter is a number of experiments by hop when the last
+		// node got data.
+		LatencyCounter map[int]int
+		LastCounter    map[int]int
+
+		// Messages is a sum of message counters of all experiments.
+		Messages Stat
+
+		// InDegreeCounter is a number of alive nodes by in-degree of
+		// partial views over all experiments, ClusteringSum is a sum of
+		// clustering coefficients of ViewCounter experiments.
+		InDegreeCounter map[int]int
+		ClusteringSum   float64
+		ViewCounter     int
+
+		nodeLatency  []int // sum of hops when node got data by node id
+		nodeInformed []int // number of experiments where node got data
+
+		coverage []int // sum of coverage of experiments that reached epoch
+		finished []int // sum of final coverage of experiments ended at epoch
 	}
 )
 
+// account classifies messages received by node in the epoch, informed
+// reports that node had data before the epoch.
+func (s *Stat) account(informed bool, received int) {
+	if informed {
+		s.Stale += received
+		s.Reused += received
+		return
+	}
+	s.Delivered++
+	s.Concurrent += received - 1
+	s.Reused += received - 1
+}
+
+// Add sums message counters of other stat, Coverage is not changed.
+func (s *Stat) Add(o Stat) {
+	s.Sent += o.Sent
+	s.Reused += o.Reused
+	s.Delivered += o.Delivered
+	s.Stale += o.Stale
+	s.Concurrent += o.Concurrent
+	s.Excluded += o.Excluded
+}
+
 func (c *EpochCounter) Inc(id int) {
 	c.Mu.Lock()
 	defer c.Mu.Unlock()
@@ -38,3 +116,203 @@ func (c *EpochCounter) IncInfiniteCounter() {
 	defer c.Mu.Unlock()
 	c.InfCounter++
 }
+
+// AddStalled accounts not filled experiment which stopped propagation
+// with the final coverage.
+func (c *EpochCounter) AddStalled(coverage int) {
+	c.Mu.Lock()
+	defer c.Mu.Unlock()
+	c.StallCounter++
+	c.StallCoverage += coverage
+}
+
+// IncMissed accounts experiment finished with number of nodes without data.
+func (c *EpochCounter) IncMissed(missed int) {
+	c.Mu.Lock()
+	defer c.Mu.Unlock()
+
+	if c.MissCounter == nil {
+		c.MissCounter = make(map[int]int)
+	}
+	c.MissCounter[missed]++
+}
+
+// AddLoad accounts per-node message counters of single experiment.
+func (c *EpochCounter) AddLoad(l Load) {
+	c.Mu.Lock()
+	defer c.Mu.Unlock()
+
+	if c.SentLoad == nil {
+		c.SentLoad = make(map[int]int)
+		c.ReceivedLoad = make(map[int]int)
+		c.DuplicateLoad = make(map[int]int)
+	}
+	for i := range l.Sent {
+		c.SentLoad[l.Sent[i]]++
+		c.ReceivedLoad[l.Received[i]]++
+		c.DuplicateLoad[l.Duplicates[i]]++
+	}
+}
+
+// AddLatency accounts hops when each node got data in single experiment,
+// see Network.InfectionEpochs. Nodes started propagation are not counted
+// in LatencyCounter.
+func (c *EpochCounter) AddLatency(hops map[int]int, size int) {
+	c.Mu.Lock()
+	defer c.Mu.Unlock()
+
+	if c.LatencyCounter == nil {
+		c.LatencyCounter = make(map[int]int)
+		c.LastCounter = make(map[int]int)
+	}
+	for len(c.nodeLatency) < size {
+		c.nodeLatency = append(c.nodeLatency, 0)
+		c.nodeInformed = append(c.nodeInformed, 0)
+	}
+	last := 0
+	for node, hop := range hops {
+		if hop > 0 {
+			c.LatencyCounter[hop]++
+		}
+		if hop > last {
+			last = hop
+		}
+		c.nodeLatency[node] += hop
+		c.nodeInformed[node]++
+	}
+	c.LastCounter[last]++
+}
+
+// NodeLatency returns average hop when each node got data, -1 for nodes
+// which never got it.
+func (c *EpochCounter) NodeLatency() []float64 {
+	c.Mu.Lock()
+	defer c.Mu.Unlock()
+
+	result := make([]float64, len(c.nodeLatency))
+	for i := range result {
+		result[i] = -1
+		if c.nodeInformed[i] > 0 {
+			result[i] = float64(c.nodeLatency[i]) / float64(c.nodeInformed[i])
+		}
+	}
+	return result
+}
+
+// AddViews accounts quality of partial views of single experiment.
+func (c *EpochCounter) AddViews(v Views) {
+	c.Mu.Lock()
+	defer c.Mu.Unlock()
+
+	if c.InDegreeCounter == nil {
+		c.InDegreeCounter = make(map[int]int)
+	}
+	for _, degree := range v.InDegree {
+		c.InDegreeCounter[degree]++
+	}
+	c.ClusteringSum += v.Clustering
+	c.ViewCounter++
+}
+
+// AddMessages accounts message counters of single experiment.
+func (c *EpochCounter) AddMessages(s Stat) {
+	c.Mu.Lock()
+	defer c.Mu.Unlock()
+	c.Messages.Add(s)
+}
+
+// AddCoverage accumulates coverage curve of single experiment, where
+// curve[i] is a number of nodes with data after i propagation hops.
+func (c *EpochCounter) AddCoverage(curve []int) {
+	if len(curve) == 0 {
+		return
+	}
+	c.Mu.Lock()
+	defer c.Mu.Unlock()
+
+	for len(c.coverage) < len(curve) {
+		c.coverage = append(c.coverage, 0)
+	}
+	for len(c.finished) <= len(curve) {
+		c.finished = append(c.finished, 0)
+	}
+	for i, v := range curve {
+		c.coverage[i] += v
+	}
+	c.finished[len(curve)] += curve[len(curve)-1]
+}
+
+// CoverageCurve returns average coverage after each hop over numexp
+// experiments. Experiments that ended earlier keep their final coverage.
+func (c *EpochCounter) CoverageCurve(numexp int) []float64 {
+	c.Mu.Lock()
+	defer c.Mu.Unlock()
+
+	if numexp <= 0 {
+		return nil
+	}
+	result := make([]float64, len(c.coverage))
+	done := 0
+	for i := range c.coverage {
+		done += c.finished[i]
+		result[i] = float64(c.coverage[i]+done) / float64(numexp)
+	}
+	return result
+}
+
+// Total returns number of accounted experiments.
+func (c *EpochCounter) Total() int {
+	c.Mu.Lock()
+	defer c.Mu.Unlock()
+
+	total := c.InfCounter
+	for _, v := range c.Counter {
+		total += v
+	}
+	return total
+}
+
+// Snapshot returns a copy of counters collected so far, so it can be
+// inspected while experiments are still running.
+func (c *EpochCounter) Snapshot() EpochCounter {
+	c.Mu.Lock()
+	defer c.Mu.Unlock()
+
+	s := EpochCounter{
+		Mu:         new(sync.Mutex),
+		Counter:    make(map[int]int, len(c.Counter)),
+		ReCounter:  c.ReCounter,
+		InfCounter: c.InfCounter,
+		coverage:   append([]int(nil), c.coverage...),
+		finished:   append([]int(nil), c.finished...),
+
+		StallCounter:  c.StallCounter,
+		StallCoverage: c.StallCoverage,
+		MissCounter:   copyCounter(c.MissCounter),
+		SentLoad:      copyCounter(c.SentLoad),
+		ReceivedLoad:  copyCounter(c.ReceivedLoad),
+		DuplicateLoad: copyCounter(c.DuplicateLoad),
+
+		Messages:       c.Messages,
+		LatencyCounter: copyCounter(c.LatencyCounter),
+		LastCounter:    copyCounter(c.LastCounter),
+		nodeLatency:    append([]int(nil), c.nodeLatency...),
+		nodeInformed:   append([]int(nil), c.nodeInformed...),
+
+		InDegreeCounter: copyCounter(c.InDegreeCounter),
+		ClusteringSum:   c.ClusteringSum,
+		ViewCounter:     c.ViewCounter,
+	}
+	for k, v := range c.Counter {
+		s.Counter[k] = v
+	}
+	return s
+}
+
+func copyCounter(m map[int]int) map[int]int {
+	c := make(map[int]int, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
+}
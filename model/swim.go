@@ -0,0 +1,416 @@
+package model
+
+/*
+	SWIM (Das, Gupta and Motivala) membership with failure detector. Every
+	node knows all nodes and their states: alive, suspect or dead with
+	incarnation number. In a protocol period every alive node in id order:
+	`- pings the next member of its round-robin list, which is shuffled
+	   when exhausted, and waits for ack
+	`- without ack sends PING-REQ to k random members, which ping the
+	   target and relay its ack
+	`- without any ack marks the target suspect
+	Member suspected for suspicion timeout periods is confirmed dead and
+	never probed again. Membership updates are piggybacked on pings,
+	PING-REQs and acks: every message carries up to maxPiggyback the most
+	recent updates of the sender, every update is sent retransmitFactor
+	times log10 of size times as in memberlist library. Node which learns it is suspected or dead
+	refutes it with alive update of increased incarnation, alive update of
+	higher incarnation overrides suspect and dead states. Messages are
+	instant, but may be lost by the loss model, crashed nodes do not reply.
+*/
+
+import "math"
+
+const (
+	// DefaultIndirectProbes is a number of members asked to ping the
+	// target without ack.
+	DefaultIndirectProbes = 3
+
+	maxPiggyback     = 20 // membership updates per message, about UDP packet of memberlist
+	retransmitFactor = 4  // transmissions of update relative to log10 of size
+	suspicionFactor  = 4  // suspicion timeout relative to log10 of size
+)
+
+const (
+	swimAlive = iota
+	swimSuspect
+	swimDead
+)
+
+type (
+	// Swim keeps membership lists of every node of the network.
+	Swim struct {
+		net         *Network
+		members     [][]swimMember // state of every node by node
+		updates     [][]swimUpdate // updates to piggyback by node
+		suspects    [][]int        // suspected members by node
+		probes      [][]int        // rest of round-robin list by node
+		incarnation []int
+		loss        Loss
+		probesNum   int // k, see SetIndirectProbes
+		timeout     int // see SetSuspicionTimeout
+		retransmit  int
+		period      int
+
+		crashed   map[int]bool
+		crashAt   map[int]int         // period when node crashed
+		suspected map[int]int         // period when crashed node was suspected first
+		deadBy    map[int]int         // number of nodes confirmed crashed node
+		confirmed map[int]int         // period when all alive nodes confirmed crashed node
+		falsely   map[swimUpdate]bool // false suspicions and confirmations
+	}
+
+	swimMember struct {
+		state       int
+		incarnation int
+		since       int // period when member was suspected
+	}
+
+	swimUpdate struct {
+		node        int
+		state       int
+		incarnation int
+		left        int // transmissions left
+	}
+
+	// SwimStat is a message accounting of protocol periods.
+	SwimStat struct {
+		Ping    int `json:"ping"`
+		PingReq int `json:"ping_req"`
+		Ack     int `json:"ack"`
+		Lost    int `json:"lost"`    // messages lost or sent to crashed nodes
+		Updates int `json:"updates"` // piggybacked membership updates
+
+		// FalseSuspicions and FalsePositives are numbers of incarnations
+		// of alive nodes suspected and confirmed dead by some member.
+		FalseSuspicions int `json:"false_suspicions"`
+		FalsePositives  int `json:"false_positives"`
+	}
+)
+
+// NewSwim creates membership lists of all nodes of the network, all nodes
+// are alive. Random source of the network is used.
+func NewSwim(net *Network) *Swim {
+	size := len(net.Topology)
+	sw := &Swim{
+		net:         net,
+		members:     make([][]swimMember, size),
+		updates:     make([][]swimUpdate, size),
+		suspects:    make([][]int, size),
+		probes:      make([][]int, size),
+		incarnation: make([]int, size),
+		loss:        reliable{},
+		probesNum:   DefaultIndirectProbes,
+		timeout:     DefaultSuspicionTimeout(size),
+		retransmit:  retransmitFactor * int(math.Ceil(math.Log10(float64(size+1)))),
+		crashed:     make(map[int]bool),
+		crashAt:     make(map[int]int),
+		suspected:   make(map[int]int),
+		deadBy:      make(map[int]int),
+		confirmed:   make(map[int]int),
+		falsely:     make(map[swimUpdate]bool),
+	}
+	for node := range sw.members {
+		sw.members[node] = make([]swimMember, size)
+	}
+	return sw
+}
+
+// SetIndirectProbes sets number of members asked to ping the target
+// without ack, see DefaultIndirectProbes.
+func (sw *Swim) SetIndirectProbes(k int) {
+	sw.probesNum = k
+}
+
+// SetSuspicionTimeout sets number of periods before suspect member is
+// confirmed dead, see DefaultSuspicionTimeout.
+func (sw *Swim) SetSuspicionTimeout(periods int) {
+	sw.timeout = periods
+}
+
+// DefaultSuspicionTimeout returns number of periods before suspect member
+// is confirmed dead for network of the size: 4*ceil(log10(size)) as in
+// memberlist library, so there is time to disseminate refutation.
+func DefaultSuspicionTimeout(size int) int {
+	timeout := suspicionFactor * int(math.Ceil(math.Log10(float64(size))))
+	if timeout < 1 {
+		timeout = 1
+	}
+	return timeout
+}
+
+// SetLoss makes messages lost by the loss model, nil stands for reliable
+// links.
+func (sw *Swim) SetLoss(l Loss) {
+	if l == nil {
+		l = reliable{}
+	}
+	sw.loss = l
+}
+
+// CrashRandom crashes count random alive nodes before the next period and
+// returns them.
+func (sw *Swim) CrashRandom(count int) []int {
+	var nodes []int
+	for len(nodes) < count && len(sw.crashed) < len(sw.members) {
+		node := sw.net.intn(len(sw.members))
+		if sw.crashed[node] {
+			continue
+		}
+		sw.crashed[node] = true
+		sw.crashAt[node] = sw.period
+		nodes = append(nodes, node)
+	}
+	return nodes
+}
+
+// Alive returns number of nodes which did not crash.
+func (sw *Swim) Alive() int {
+	return len(sw.members) - len(sw.crashed)
+}
+
+// Suspected returns number of periods since crash of the node until some
+// member suspected it, false if nobody did.
+func (sw *Swim) Suspected(node int) (int, bool) {
+	period, ok := sw.suspected[node]
+	return period - sw.crashAt[node] + 1, ok
+}
+
+// Confirmed returns number of periods since crash of the node until all
+// alive nodes confirmed it dead, false if some did not.
+func (sw *Swim) Confirmed(node int) (int, bool) {
+	period, ok := sw.confirmed[node]
+	return period - sw.crashAt[node] + 1, ok
+}
+
+// Add sums counters of other stat.
+func (s *SwimStat) Add(o SwimStat) {
+	s.Ping += o.Ping
+	s.PingReq += o.PingReq
+	s.Ack += o.Ack
+	s.Lost += o.Lost
+	s.Updates += o.Updates
+	s.FalseSuspicions += o.FalseSuspicions
+	s.FalsePositives += o.FalsePositives
+}
+
+// Period runs protocol period of all alive nodes and confirms suspects
+// which timed out.
+func (sw *Swim) Period() SwimStat {
+	var s SwimStat
+	for node := range sw.members {
+		if sw.crashed[node] {
+			continue
+		}
+		target, ok := sw.target(node)
+		if !ok {
+			continue
+		}
+		if !sw.probe(node, target, &s) && !sw.probeIndirect(node, target, &s) {
+			sw.suspect(node, target, &s)
+		}
+	}
+	for node := range sw.members {
+		if !sw.crashed[node] {
+			sw.expire(node, &s)
+		}
+	}
+	sw.period++
+	return s
+}
+
+// target returns the next member of the round-robin list of the node,
+// false if all members are dead.
+func (sw *Swim) target(node int) (int, bool) {
+	for refilled := false; ; refilled = true {
+		for len(sw.probes[node]) > 0 {
+			peer := sw.probes[node][0]
+			sw.probes[node] = sw.probes[node][1:]
+			if peer != node && sw.members[node][peer].state != swimDead {
+				return peer, true
+			}
+		}
+		if refilled {
+			return 0, false
+		}
+		sw.probes[node] = sw.net.perm(len(sw.members))
+	}
+}
+
+// probe pings the target and reports that ack came back.
+func (sw *Swim) probe(node, target int, s *SwimStat) bool {
+	s.Ping++
+	if !sw.send(node, target, s) {
+		return false
+	}
+	s.Ack++
+	return sw.send(target, node, s)
+}
+
+// probeIndirect sends PING-REQ to k random members and reports that some
+// of them relayed ack of the target.
+func (sw *Swim) probeIndirect(node, target int, s *SwimStat) bool {
+	acked := false
+	for _, helper := range sw.helpers(node, target) {
+		s.PingReq++
+		if !sw.send(node, helper, s) || !sw.probe(helper, target, s) {
+			continue
+		}
+		s.Ack++
+		if sw.send(helper, node, s) {
+			acked = true
+		}
+	}
+	return acked
+}
+
+// helpers returns up to k random members of the node, which are not dead,
+// except the target.
+func (sw *Swim) helpers(node, target int) []int {
+	var nodes []int
+	for _, peer := range sw.net.perm(len(sw.members)) {
+		if len(nodes) == sw.probesNum {
+			break
+		}
+		if peer != node && peer != target && sw.members[node][peer].state != swimDead {
+			nodes = append(nodes, peer)
+		}
+	}
+	return nodes
+}
+
+// send delivers message with piggybacked updates of the sender, reports
+// false if the message is lost or the receiver crashed.
+func (sw *Swim) send(sender, receiver int, s *SwimStat) bool {
+	updates := sw.piggyback(sender)
+	s.Updates += len(updates)
+	if sw.loss.Lost(sender) || sw.crashed[receiver] {
+		s.Lost++
+		return false
+	}
+	for _, u := range updates {
+		sw.apply(receiver, u)
+	}
+	return true
+}
+
+// piggyback returns the most recent updates of the node and removes ones
+// sent enough times.
+func (sw *Swim) piggyback(node int) []swimUpdate {
+	buf := sw.updates[node]
+	var updates []swimUpdate
+	for i := len(buf) - 1; i >= 0 && len(updates) < maxPiggyback; i-- {
+		updates = append(updates, buf[i])
+		buf[i].left--
+	}
+	kept := buf[:0]
+	for _, u := range buf {
+		if u.left > 0 {
+			kept = append(kept, u)
+		}
+	}
+	sw.updates[node] = kept
+	return updates
+}
+
+// enqueue adds update to piggyback by the node instead of older update of
+// the same member.
+func (sw *Swim) enqueue(node int, u swimUpdate) {
+	buf := sw.updates[node]
+	for i := range buf {
+		if buf[i].node == u.node {
+			buf = append(buf[:i], buf[i+1:]...)
+			break
+		}
+	}
+	u.left = sw.retransmit
+	sw.updates[node] = append(buf, u)
+}
+
+// apply merges update into membership list of the node, new information
+// is piggybacked further. Node refutes suspicion of itself.
+func (sw *Swim) apply(node int, u swimUpdate) {
+	if u.node == node {
+		if u.state != swimAlive && u.incarnation >= sw.incarnation[node] {
+			sw.incarnation[node] = u.incarnation + 1
+			sw.enqueue(node, swimUpdate{node: node, state: swimAlive, incarnation: sw.incarnation[node]})
+		}
+		return
+	}
+	m := sw.members[node][u.node]
+	switch {
+	case u.state == swimAlive && u.incarnation > m.incarnation:
+	case u.state == swimSuspect && m.state == swimAlive && u.incarnation >= m.incarnation:
+	case u.state == swimSuspect && m.state == swimSuspect && u.incarnation > m.incarnation:
+	case u.state == swimDead && m.state != swimDead && u.incarnation >= m.incarnation:
+	default:
+		return
+	}
+	sw.set(node, u.node, u.state, u.incarnation)
+	sw.enqueue(node, u)
+}
+
+// suspect marks alive target suspect after failed probes.
+func (sw *Swim) suspect(node, target int, s *SwimStat) {
+	m := sw.members[node][target]
+	if m.state != swimAlive {
+		return
+	}
+	sw.set(node, target, swimSuspect, m.incarnation)
+	sw.enqueue(node, swimUpdate{node: target, state: swimSuspect, incarnation: m.incarnation})
+	if sw.falsify(swimUpdate{node: target, state: swimSuspect, incarnation: m.incarnation}) {
+		s.FalseSuspicions++
+	}
+}
+
+// expire confirms dead members suspected for suspicion timeout periods.
+func (sw *Swim) expire(node int, s *SwimStat) {
+	var kept []int
+	for _, peer := range sw.suspects[node] {
+		m := sw.members[node][peer]
+		switch {
+		case m.state != swimSuspect:
+		case sw.period-m.since+1 < sw.timeout:
+			kept = append(kept, peer)
+		default:
+			sw.set(node, peer, swimDead, m.incarnation)
+			sw.enqueue(node, swimUpdate{node: peer, state: swimDead, incarnation: m.incarnation})
+			if sw.falsify(swimUpdate{node: peer, state: swimDead, incarnation: m.incarnation}) {
+				s.FalsePositives++
+			}
+		}
+	}
+	sw.suspects[node] = kept
+}
+
+// falsify reports that the update about alive node is false and was not
+// made before.
+func (sw *Swim) falsify(u swimUpdate) bool {
+	if sw.crashed[u.node] || sw.falsely[u] {
+		return false
+	}
+	sw.falsely[u] = true
+	return true
+}
+
+// set changes state of the member in list of the node and tracks detection
+// of crashed members.
+func (sw *Swim) set(node, member, state, incarnation int) {
+	m := &sw.members[node][member]
+	if state == swimSuspect && m.state != swimSuspect {
+		m.since = sw.period
+		sw.suspects[node] = append(sw.suspects[node], member)
+	}
+	if sw.crashed[member] {
+		if _, ok := sw.suspected[member]; !ok && state != swimAlive {
+			sw.suspected[member] = sw.period
+		}
+		if state == swimDead && m.state != swimDead {
+			sw.deadBy[member]++
+			if sw.deadBy[member] == sw.Alive() {
+				sw.confirmed[member] = sw.period
+			}
+		}
+	}
+	m.state, m.incarnation = state, incarnation
+}
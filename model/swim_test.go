@@ -0,0 +1,78 @@
+package model
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSwim(t *testing.T) {
+	net, err := prepareNetwork(100)
+	require.NoError(t, err)
+	net.SetRandSource(rand.NewSource(1))
+	sw := NewSwim(&net)
+	require.Equal(t, 8, DefaultSuspicionTimeout(100))
+
+	// every alive node pings at least once per period, crashed nodes are
+	// confirmed dead by all alive nodes
+	crashed := sw.CrashRandom(5)
+	require.Len(t, crashed, 5)
+	require.Equal(t, 95, sw.Alive())
+	var s SwimStat
+	for p := 0; p < 40; p++ {
+		s.Add(sw.Period())
+	}
+	require.True(t, s.Ping >= 95*40)
+	require.True(t, s.PingReq > 0 && s.Lost > 0) // crashed targets
+	require.Equal(t, 0, s.FalseSuspicions)
+	require.Equal(t, 0, s.FalsePositives)
+	for _, node := range crashed {
+		suspected, ok := sw.Suspected(node)
+		require.True(t, ok)
+		confirmed, ok := sw.Confirmed(node)
+		require.True(t, ok)
+		require.True(t, suspected+DefaultSuspicionTimeout(100) <= confirmed)
+		for member := range sw.members {
+			if !sw.crashed[member] {
+				require.Equal(t, swimDead, sw.members[member][node].state)
+			}
+		}
+	}
+
+	// suspected alive node refutes suspicion with new incarnation
+	net, err = prepareNetwork(100)
+	require.NoError(t, err)
+	net.SetRandSource(rand.NewSource(1))
+	sw = NewSwim(&net)
+	s = SwimStat{}
+	sw.suspect(0, 1, &s)
+	require.Equal(t, 1, s.FalseSuspicions)
+	for p := 0; p < 20; p++ {
+		s.Add(sw.Period())
+	}
+	require.Equal(t, 1, sw.incarnation[1])
+	require.Equal(t, 0, s.FalsePositives)
+	for member := range sw.members {
+		if member != 1 {
+			require.Equal(t, swimAlive, sw.members[member][1].state)
+			require.Equal(t, 1, sw.members[member][1].incarnation)
+		}
+	}
+
+	// lost messages make false suspicions
+	net, err = prepareNetwork(100)
+	require.NoError(t, err)
+	net.SetRandSource(rand.NewSource(1))
+	sw = NewSwim(&net)
+	loss, err := NewLoss(UniformLoss, &net, 0.3)
+	require.NoError(t, err)
+	sw.SetLoss(loss)
+	s = SwimStat{}
+	for p := 0; p < 20; p++ {
+		s.Add(sw.Period())
+	}
+	require.True(t, s.Lost > 0)
+	require.True(t, s.PingReq > 0)
+	require.True(t, s.FalseSuspicions > 0)
+}
@@ -0,0 +1,41 @@
+package model
+
+/*
+	Hop counter of propagated messages. Node keeps hop of the first message
+	which brought data to it (zero for nodes started propagation), messages
+	sent by the node carry the next hop. With TTL limit node does not
+	forward data when its hop reached TTL, so data travels at most TTL hops.
+
+	TTL bounds coverage of algorithms sending data once. Nodes of forever
+	algorithms below TTL keep sending, so TTL bounds only length of paths.
+*/
+
+// SetTTL limits number of hops data travels, zero stands for no limit.
+func (n *Network) SetTTL(ttl int) {
+	n.ttl = ttl
+}
+
+// Hop returns hop of the first message which brought data to the node.
+func (n Network) Hop(node int) int {
+	return n.hops[node]
+}
+
+// expired reports that node can not forward data anymore because of TTL.
+func (n Network) expired(node int) bool {
+	return n.ttl > 0 && n.hops[node] >= n.ttl
+}
+
+// trackHops sets hop of nodes which got the first message from sender.
+func (n *Network) trackHops(sender int, nodes []int) {
+	if n.hops == nil {
+		n.hops = make(map[int]int, len(n.Topology))
+	}
+	if _, ok := n.hops[sender]; !ok {
+		n.hops[sender] = 0
+	}
+	for _, node := range nodes {
+		if _, ok := n.hops[node]; !ok {
+			n.hops[node] = n.hops[sender] + 1
+		}
+	}
+}
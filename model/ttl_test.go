@@ -0,0 +1,53 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNetwork_TTL(t *testing.T) {
+	// hop of node is hop of the first message brought data to it
+	net, err := prepareNetwork(100)
+	require.NoError(t, err)
+	net.RunEpochNaiveOnce(5, 0)
+	net.RunEpochNaiveOnce(5, 1)
+	require.Equal(t, 0, net.Hop(0))
+	for _, node := range net.History[0][0] {
+		require.Equal(t, 1, net.Hop(node))
+	}
+	for _, voted := range net.History[1] {
+		for _, node := range voted {
+			require.True(t, net.Hop(node) <= 2) // the source keeps hop 0
+		}
+	}
+
+	// data travels at most TTL hops
+	net, err = prepareNetwork(100)
+	require.NoError(t, err)
+	net.SetTTL(1)
+	require.False(t, net.IsQuiescentOnce())
+	net.RunEpochNaiveOnce(5, 0)
+	require.True(t, net.IsQuiescentOnce()) // informed nodes are expired
+	s := net.RunEpochNaiveOnce(5, 1)
+	require.Equal(t, 0, s.Sent)
+	require.Equal(t, 6, net.CountCoverage())
+
+	// forever algorithm keeps sending, but not from expired nodes
+	net, err = prepareNetwork(100)
+	require.NoError(t, err)
+	net.SetTTL(2)
+	for epoch := 0; epoch < 10; epoch++ {
+		net.RunEpochNaiveForever(3, epoch)
+	}
+	for _, senders := range net.History {
+		for sender := range senders {
+			require.True(t, net.Hop(sender) < 2)
+		}
+	}
+	for node, v := range net.Topology {
+		if v != 0 {
+			require.True(t, net.Hop(node) <= 2)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package model
+
+type (
+	// Views describes quality of partial views of alive nodes: in-degree
+	// of node is a number of views containing it, clustering is a mean
+	// local clustering coefficient of undirected graph of views.
+	Views struct {
+		InDegree   []int
+		Clustering float64
+	}
+)
+
+// Views returns quality of views of the network membership, false for
+// global membership.
+func (n *Network) Views() (Views, bool) {
+	sampler := n.PeerSampler()
+	if len(n.Topology) == 0 || sampler.View(0) == nil {
+		return Views{}, false
+	}
+	links := make([]map[int]bool, len(n.Topology))
+	for node := range links {
+		links[node] = make(map[int]bool)
+	}
+	v := Views{InDegree: make([]int, 0, n.Alive())}
+	in := make([]int, len(n.Topology))
+	for node := range links {
+		if n.down[node] {
+			continue
+		}
+		for _, peer := range sampler.View(node) {
+			if n.down[peer] {
+				continue
+			}
+			in[peer]++
+			links[node][peer] = true
+			links[peer][node] = true
+		}
+	}
+
+	nodes := 0
+	for node := range links {
+		if n.down[node] {
+			continue
+		}
+		v.InDegree = append(v.InDegree, in[node])
+		k := len(links[node])
+		if k < 2 {
+			continue
+		}
+		closed := 0
+		for a := range links[node] {
+			for b := range links[node] {
+				if a < b && links[a][b] {
+					closed++
+				}
+			}
+		}
+		v.Clustering += float64(closed) / float64(k*(k-1)/2)
+		nodes++
+	}
+	if nodes > 0 {
+		v.Clustering /= float64(nodes)
+	}
+	return v, true
+}
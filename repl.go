@@ -0,0 +1,618 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gossipmodel/model"
+	"gossipmodel/report"
+	"gossipmodel/runner"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/abiosoft/ishell"
+)
+
+type (
+	// session keeps REPL settings and results of previous runs.
+	session struct {
+		Config  runner.Config   `json:"config"`
+		History []runner.Result `json:"history"`
+
+		sweep *runner.SweepResult // last sweep, not saved
+	}
+)
+
+var (
+	errNoResults = errors.New("no results yet, use 'run' first")
+	errNoSweep   = errors.New("no sweep results yet, use 'sweep' first")
+)
+
+// newShell creates interactive model runner with initial settings.
+func newShell(cfg runner.Config) *ishell.Shell {
+	s := &session{Config: cfg}
+	shell := ishell.New()
+
+	shell.AddCmd(&ishell.Cmd{
+		Name:      "run",
+		Help:      "run gossip experiments, e.g. 'run size=200 fanout=5'",
+		LongHelp:  "Runs experiments with current settings, which can be overridden for\nthis run with param=value arguments.",
+		Completer: paramsCompleter,
+		Func: func(c *ishell.Context) {
+			cfg, err := s.inline(c.Args)
+			if err == nil {
+				var res runner.Result
+				if res, err = runExperiment(context.Background(), cfg, false, false); err == nil {
+					s.History = append(s.History, res)
+				}
+			}
+			printErr(c, err)
+		},
+	})
+	shell.AddCmd(&ishell.Cmd{
+		Name:      "replay",
+		Help:      "run single experiment and replay it epoch by epoch",
+		Completer: paramsCompleter,
+		Func: func(c *ishell.Context) {
+			cfg, err := s.inline(c.Args)
+			if err != nil {
+				printErr(c, err)
+				return
+			}
+			c.ShowPrompt(false)
+			defer c.ShowPrompt(true)
+
+			cfg.Experiments = 1
+			cfg.Record = true
+			replay(c, runner.Single(cfg).Frames)
+		},
+	})
+	shell.AddCmd(&ishell.Cmd{
+		Name:      "set",
+		Help:      "set parameter, e.g. 'set fanout 5'",
+		Completer: setCompleter,
+		Func: func(c *ishell.Context) {
+			if len(c.Args) != 2 {
+				c.Println("Usage: set <" + strings.Join(runner.Params, "|") + "> <value>")
+				return
+			}
+			printErr(c, s.Config.Set(c.Args[0], c.Args[1]))
+		},
+	})
+	shell.AddCmd(&ishell.Cmd{
+		Name:      "unset",
+		Help:      "reset parameter to default value, 'unset all' resets everything",
+		Completer: unsetCompleter,
+		Func: func(c *ishell.Context) {
+			if len(c.Args) != 1 {
+				c.Println("Usage: unset <" + strings.Join(runner.Params, "|") + "|all>")
+				return
+			}
+			if c.Args[0] == "all" {
+				s.Config = runner.DefaultConfig()
+				return
+			}
+			printErr(c, s.Config.Reset(c.Args[0]))
+		},
+	})
+	shell.AddCmd(&ishell.Cmd{
+		Name:      "show",
+		Help:      "show parameters",
+		Completer: showCompleter,
+		Func: func(c *ishell.Context) {
+			params := runner.Params
+			if len(c.Args) > 0 {
+				params = c.Args
+			}
+			for _, p := range params {
+				v, err := s.Config.Get(p)
+				if err != nil {
+					printErr(c, err)
+					return
+				}
+				c.Printf("%12s: %s\n", p, v)
+			}
+		},
+	})
+	shell.AddCmd(&ishell.Cmd{
+		Name:      "sweep",
+		Help:      "run experiments over parameter ranges, e.g. 'sweep size 50:200:50 fanout 2:10'",
+		Completer: showCompleter,
+		Func: func(c *ishell.Context) {
+			res, err := s.runSweep(c.Args)
+			if err != nil {
+				printErr(c, err)
+				return
+			}
+			buf := new(bytes.Buffer)
+			report.SweepText(buf, res)
+			c.Print(buf.String())
+		},
+	})
+	shell.AddCmd(&ishell.Cmd{
+		Name:      "compare",
+		Help:      "run paired experiments with several algorithms or settings, e.g. 'compare naive-once vector-once' or 'compare fanout=3 fanout=5'",
+		Completer: algorithmCompleter,
+		Func: func(c *ishell.Context) {
+			if len(c.Args) < 2 {
+				c.Println("Usage: compare <algorithm|param=value> <algorithm|param=value> [...]")
+				return
+			}
+			printErr(c, s.compare(c, c.Args))
+		},
+	})
+	shell.AddCmd(&ishell.Cmd{
+		Name: "percolation",
+		Help: "scan forwarding probabilities of gossip1, gossip2 or gossip-edge, e.g. 'percolation 20'",
+		Func: func(c *ishell.Context) {
+			steps := 10
+			if len(c.Args) > 0 {
+				var err error
+				if steps, err = strconv.Atoi(c.Args[0]); err != nil {
+					printErr(c, err)
+					return
+				}
+			}
+			res, err := runner.PercolationScan(context.Background(), s.Config, steps)
+			if err != nil {
+				printErr(c, err)
+				return
+			}
+			buf := new(bytes.Buffer)
+			report.Percolation(buf, res)
+			c.Print(buf.String())
+		},
+	})
+	shell.AddCmd(&ishell.Cmd{
+		Name: "plumtree",
+		Help: "run broadcasts over Plumtree and compare messages with naive-once, e.g. 'plumtree 10 5' crashes 5 nodes in the middle",
+		Func: func(c *ishell.Context) {
+			pcfg := runner.PlumtreeConfig{Broadcasts: 10}
+			for i, v := range []*int{&pcfg.Broadcasts, &pcfg.Crash, &pcfg.GraftTimeout} {
+				if len(c.Args) <= i {
+					break
+				}
+				var err error
+				if *v, err = strconv.Atoi(c.Args[i]); err != nil {
+					printErr(c, err)
+					return
+				}
+			}
+			res, err := runner.Plumtree(context.Background(), s.Config, pcfg)
+			if err != nil {
+				printErr(c, err)
+				return
+			}
+			buf := new(bytes.Buffer)
+			report.Plumtree(buf, res)
+			c.Print(buf.String())
+		},
+	})
+	shell.AddCmd(&ishell.Cmd{
+		Name: "swim",
+		Help: "run periods of SWIM failure detector, e.g. 'swim 50 10 burst 0.05' crashes 10 nodes and loses 5% of messages in bursts",
+		Completer: func(args []string) []string {
+			if len(args) == 2 {
+				return model.LossNames()
+			}
+			return nil
+		},
+		Func: func(c *ishell.Context) {
+			scfg := runner.SwimConfig{Periods: 50, IndirectProbes: model.DefaultIndirectProbes}
+			for i, v := range []*int{&scfg.Periods, &scfg.Crash} {
+				if len(c.Args) <= i {
+					break
+				}
+				var err error
+				if *v, err = strconv.Atoi(c.Args[i]); err != nil {
+					printErr(c, err)
+					return
+				}
+			}
+			if len(c.Args) > 2 {
+				scfg.Loss = c.Args[2]
+			}
+			if len(c.Args) > 3 {
+				var err error
+				if scfg.LossRate, err = strconv.ParseFloat(c.Args[3], 64); err != nil {
+					printErr(c, err)
+					return
+				}
+			}
+			res, err := runner.Swim(context.Background(), s.Config, scfg)
+			if err != nil {
+				printErr(c, err)
+				return
+			}
+			buf := new(bytes.Buffer)
+			report.Swim(buf, res)
+			c.Print(buf.String())
+		},
+	})
+	shell.AddCmd(&ishell.Cmd{
+		Name: "scuttlebutt",
+		Help: "run epochs of scuttlebutt, anti-entropy and push state reconciliation, e.g. 'scuttlebutt 40 16' with MTU of 16 deltas",
+		Func: func(c *ishell.Context) {
+			rcfg := runner.ReconcileConfig{Epochs: 40, UpdateRate: 0.5}
+			for i, v := range []*int{&rcfg.Epochs, &rcfg.MTU, &rcfg.Keys} {
+				if len(c.Args) <= i {
+					break
+				}
+				var err error
+				if *v, err = strconv.Atoi(c.Args[i]); err != nil {
+					printErr(c, err)
+					return
+				}
+			}
+			res, err := runner.Reconcile(context.Background(), s.Config, rcfg)
+			if err != nil {
+				printErr(c, err)
+				return
+			}
+			buf := new(bytes.Buffer)
+			report.Reconcile(buf, res)
+			c.Print(buf.String())
+		},
+	})
+	shell.AddCmd(&ishell.Cmd{
+		Name:      "rare",
+		Help:      "estimate probability that network is not filled, e.g. 'rare fanout=20 experiments=10000'",
+		Completer: paramsCompleter,
+		Func: func(c *ishell.Context) {
+			cfg, err := s.inline(c.Args)
+			if err != nil {
+				printErr(c, err)
+				return
+			}
+			res, err := runner.RareEvent(context.Background(), cfg)
+			if err != nil {
+				printErr(c, err)
+				return
+			}
+			buf := new(bytes.Buffer)
+			report.RareEvent(buf, res)
+			c.Print(buf.String())
+		},
+	})
+	shell.AddCmd(&ishell.Cmd{
+		Name: "reliability",
+		Help: "show partial coverage metrics of the last run, e.g. 'reliability 99 99.9'",
+		Func: func(c *ishell.Context) {
+			if len(s.History) == 0 {
+				printErr(c, errNoResults)
+				return
+			}
+			levels, err := parseLevels(strings.Join(c.Args, ","))
+			if err != nil {
+				printErr(c, err)
+				return
+			}
+			buf := new(bytes.Buffer)
+			report.Reliability(buf, s.History[len(s.History)-1], levels)
+			c.Print(buf.String())
+		},
+	})
+	shell.AddCmd(&ishell.Cmd{
+		Name: "fairness",
+		Help: "show per-node load of the last run",
+		Func: func(c *ishell.Context) {
+			if len(s.History) == 0 {
+				printErr(c, errNoResults)
+				return
+			}
+			buf := new(bytes.Buffer)
+			report.Load(buf, s.History[len(s.History)-1])
+			c.Print(buf.String())
+		},
+	})
+	shell.AddCmd(&ishell.Cmd{
+		Name: "latency",
+		Help: "show hops when nodes got data in the last run",
+		Func: func(c *ishell.Context) {
+			if len(s.History) == 0 {
+				printErr(c, errNoResults)
+				return
+			}
+			buf := new(bytes.Buffer)
+			report.Latency(buf, s.History[len(s.History)-1])
+			c.Print(buf.String())
+		},
+	})
+	shell.AddCmd(&ishell.Cmd{
+		Name: "views",
+		Help: "show in-degree and clustering of membership views of the last run",
+		Func: func(c *ishell.Context) {
+			if len(s.History) == 0 {
+				printErr(c, errNoResults)
+				return
+			}
+			buf := new(bytes.Buffer)
+			report.Views(buf, s.History[len(s.History)-1])
+			c.Print(buf.String())
+		},
+	})
+	shell.AddCmd(&ishell.Cmd{
+		Name:     "export",
+		Help:     "export results: 'export svg <dir>', 'export json <file>', 'export dot <file>', 'export sweep <file>' or 'export sweep-coverage <file>'",
+		LongHelp: "svg            - charts of the last run\njson           - results of the last run\ndot            - propagation graph of new single experiment\nsweep          - chart of mean hops of the last sweep\nsweep-coverage - chart of mean coverage of the last sweep",
+		Completer: func(args []string) []string {
+			if len(args) == 0 {
+				return []string{"svg", "json", "dot", "sweep", "sweep-coverage"}
+			}
+			return nil
+		},
+		Func: func(c *ishell.Context) {
+			if len(c.Args) != 2 {
+				c.Println("Usage: export <svg|json|dot|sweep|sweep-coverage> <path>")
+				return
+			}
+			printErr(c, s.export(c.Args[0], c.Args[1]))
+		},
+	})
+	shell.AddCmd(&ishell.Cmd{
+		Name: "history",
+		Help: "list results of previous runs, 'history <n>' shows the n-th one",
+		Func: func(c *ishell.Context) {
+			buf := new(bytes.Buffer)
+			if len(c.Args) == 0 {
+				for i, res := range s.History {
+					fmt.Fprintf(buf, "%3d: %s\n", i+1, summary(res))
+				}
+			} else {
+				n, err := strconv.Atoi(c.Args[0])
+				if err != nil || n < 1 || n > len(s.History) {
+					c.Println("Incorrect history number")
+					return
+				}
+				report.Text(buf, s.History[n-1])
+			}
+			c.Print(buf.String())
+		},
+	})
+	shell.AddCmd(&ishell.Cmd{
+		Name: "save",
+		Help: "save settings and history to the file",
+		Func: func(c *ishell.Context) {
+			if len(c.Args) != 1 {
+				c.Println("Usage: save <file>")
+				return
+			}
+			printErr(c, s.save(c.Args[0]))
+		},
+	})
+	shell.AddCmd(&ishell.Cmd{
+		Name: "load",
+		Help: "load settings and history from the file",
+		Func: func(c *ishell.Context) {
+			if len(c.Args) != 1 {
+				c.Println("Usage: load <file>")
+				return
+			}
+			printErr(c, s.load(c.Args[0]))
+		},
+	})
+	return shell
+}
+
+// inline returns session config overridden with param=value arguments.
+func (s *session) inline(args []string) (runner.Config, error) {
+	cfg := s.Config
+	for _, arg := range args {
+		kv := strings.SplitN(arg, "=", 2)
+		if len(kv) != 2 {
+			return cfg, fmt.Errorf("incorrect argument %q, param=value expected", arg)
+		}
+		if err := cfg.Set(kv[0], kv[1]); err != nil {
+			return cfg, fmt.Errorf("%s: %v", kv[0], err)
+		}
+	}
+	return cfg, cfg.Validate()
+}
+
+// runSweep parses `param min:max[:step]` pairs and runs sweep.
+func (s *session) runSweep(args []string) (runner.SweepResult, error) {
+	if len(args) != 2 && len(args) != 4 {
+		return runner.SweepResult{}, errors.New("usage: sweep <param> <min:max[:step]> [<param> <min:max[:step]>]")
+	}
+	var axes []runner.Axis
+	for i := 0; i < len(args); i += 2 {
+		bounds := strings.Split(args[i+1], ":")
+		nums := []int{0, 0, 1}
+		if len(bounds) < 2 || len(bounds) > 3 {
+			return runner.SweepResult{}, fmt.Errorf("incorrect range %q", args[i+1])
+		}
+		for j, b := range bounds {
+			v, err := strconv.Atoi(b)
+			if err != nil {
+				return runner.SweepResult{}, fmt.Errorf("incorrect range %q", args[i+1])
+			}
+			nums[j] = v
+		}
+		axis, err := runner.NewAxis(args[i], nums[0], nums[1], nums[2])
+		if err != nil {
+			return runner.SweepResult{}, err
+		}
+		axes = append(axes, axis)
+	}
+	res, err := runner.Sweep(context.Background(), s.Config, axes...)
+	if err != nil {
+		return res, err
+	}
+	s.sweep = &res
+	return res, nil
+}
+
+// compare runs paired experiments with the same random streams for each variant.
+func (s *session) compare(c *ishell.Context, variants []string) error {
+	cmp, err := runner.Compare(context.Background(), s.Config, variants)
+	if err != nil {
+		return err
+	}
+	buf := new(bytes.Buffer)
+	report.Comparison(buf, cmp)
+	c.Print(buf.String())
+	s.History = append(s.History, cmp.Results...)
+	return nil
+}
+
+func (s *session) export(kind, path string) error {
+	switch kind {
+	case "dot":
+		return exportDOT(path, s.Config)
+	case "sweep", "sweep-coverage":
+		if s.sweep == nil {
+			return errNoSweep
+		}
+		f, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		if kind == "sweep-coverage" {
+			return report.SweepCoverageChart(f, *s.sweep)
+		}
+		return report.SweepChart(f, *s.sweep)
+	}
+
+	if len(s.History) == 0 {
+		return errNoResults
+	}
+	last := s.History[len(s.History)-1]
+	switch kind {
+	case "svg":
+		return report.Charts(path, last)
+	case "json":
+		data, err := json.MarshalIndent(last, "", "  ")
+		if err != nil {
+			return err
+		}
+		return ioutil.WriteFile(path, data, 0644)
+	}
+	return fmt.Errorf("unknown export format %q", kind)
+}
+
+func (s *session) save(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0644)
+}
+
+func (s *session) load(path string) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	loaded := session{Config: runner.DefaultConfig()}
+	if err = json.Unmarshal(data, &loaded); err != nil {
+		return err
+	}
+	if err = loaded.Config.Validate(); err != nil {
+		return err
+	}
+	*s = loaded
+	return nil
+}
+
+// summary returns one line description of the run.
+func summary(res runner.Result) string {
+	inf := 0.0
+	if res.Completed > 0 {
+		inf = float64(res.Infinite) / float64(res.Completed) * 100
+	}
+	return fmt.Sprintf("size=%d fanout=%d node=%d algorithm=%s experiments=%d: mean hops %.3f, inf %.2f%%",
+		res.Config.Size, res.Config.Fanout, res.Config.InitialNode, res.Config.Algorithm,
+		res.Completed, res.MeanHops(), inf)
+}
+
+func printErr(c *ishell.Context, err error) {
+	if err != nil {
+		c.Println("Error:", err)
+	}
+}
+
+func paramsCompleter([]string) []string {
+	words := make([]string, 0, len(runner.Params))
+	for _, p := range runner.Params {
+		if p != "algorithm" && p != "membership" {
+			words = append(words, p+"=")
+		}
+	}
+	for _, name := range model.AlgorithmNames() {
+		words = append(words, "algorithm="+name)
+	}
+	for _, name := range model.MembershipNames() {
+		words = append(words, "membership="+name)
+	}
+	return words
+}
+
+func setCompleter(args []string) []string {
+	switch {
+	case len(args) == 0:
+		return runner.Params
+	case len(args) == 1 && args[0] == "algorithm":
+		return model.AlgorithmNames()
+	case len(args) == 1 && args[0] == "membership":
+		return model.MembershipNames()
+	}
+	return nil
+}
+
+func unsetCompleter(args []string) []string {
+	if len(args) == 0 {
+		return append([]string{"all"}, runner.Params...)
+	}
+	return nil
+}
+
+func showCompleter([]string) []string {
+	return runner.Params
+}
+
+func algorithmCompleter([]string) []string {
+	return model.AlgorithmNames()
+}
+
+// replay shows frames one by one with forward and back stepping.
+func replay(c *ishell.Context, frames []model.Frame) {
+	last := len(frames) - 1
+	cur := 0
+	for {
+		buf := new(bytes.Buffer)
+		report.Frame(buf, frames[cur], cur, last)
+		c.Println("-----------")
+		c.Print(buf.String())
+		c.Print("[n]ext, [p]rev, [f]irst, [l]ast, frame number or [q]uit: ")
+
+		cmd := strings.TrimSpace(c.ReadLine())
+		switch cmd {
+		case "", "n":
+			if cur < last {
+				cur++
+			}
+		case "p":
+			if cur > 0 {
+				cur--
+			}
+		case "f":
+			cur = 0
+		case "l":
+			cur = last
+		case "q":
+			return
+		default:
+			num, err := strconv.Atoi(cmd)
+			if err != nil || num < 0 || num > last {
+				c.Println("Incorrect command")
+				continue
+			}
+			cur = num
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package report
+
+import (
+	"gossipmodel/chart"
+	"gossipmodel/runner"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// Charts renders hop distribution and coverage curve of the run into
+// SVG files in dir.
+func Charts(dir string, res runner.Result) error {
+	if res.Completed == 0 {
+		return chart.ErrEmptyData
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	err := writeChart(filepath.Join(dir, "hops.svg"), func(w io.Writer) error {
+		return HopsChart(w, res)
+	})
+	if err != nil {
+		return err
+	}
+	return writeChart(filepath.Join(dir, "coverage.svg"), func(w io.Writer) error {
+		return CoverageChart(w, res)
+	})
+}
+
+// HopsChart renders SVG histogram of hops to fill the network.
+func HopsChart(w io.Writer, res runner.Result) error {
+	if res.Completed == 0 {
+		return chart.ErrEmptyData
+	}
+	hops := sortedHops(res)
+	labels := make([]string, 0, len(hops)+1)
+	values := make([]float64, 0, len(hops)+1)
+	for _, hop := range hops {
+		labels = append(labels, strconv.Itoa(hop))
+		values = append(values, percent(res.Hops[hop], res.Completed))
+	}
+	labels = append(labels, "inf")
+	values = append(values, percent(res.Infinite, res.Completed))
+	return chart.Histogram(w, "Hops to fill the network", "hops", "experiments, %", labels, values)
+}
+
+// CoverageChart renders SVG curve of average coverage after each hop.
+func CoverageChart(w io.Writer, res runner.Result) error {
+	xs := make([]string, len(res.Coverage))
+	for i := range res.Coverage {
+		xs[i] = strconv.Itoa(i)
+	}
+	return chart.Line(w, "Average coverage", "hops", "nodes with data", xs, res.Coverage)
+}
+
+func writeChart(path string, draw func(io.Writer) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err = draw(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
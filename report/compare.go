@@ -0,0 +1,60 @@
+package report
+
+import (
+	"fmt"
+	"gossipmodel/runner"
+	"gossipmodel/stats"
+	"io"
+)
+
+// significance is a p-value threshold to mark differences as significant.
+const significance = 0.05
+
+// Comparison writes aggregated results of each algorithm and paired tests
+// of every pair of algorithms.
+func Comparison(w io.Writer, cmp runner.Comparison) {
+	if len(cmp.Results) == 0 {
+		return
+	}
+	if cmp.Canceled {
+		fmt.Fprintln(w, "Interrupted: comparison results are partial")
+	}
+	cfg := cmp.Results[0].Config
+	fmt.Fprintf(w, "Size: %d Fan-out: %d Experiments: %d Seed: %d\n",
+		cfg.Size, cfg.Fanout, cmp.Results[0].Completed, cfg.Seed)
+	if cfg.Antithetic {
+		fmt.Fprintln(w, "Antithetic pairs: t-tests compare means of pairs")
+	}
+
+	fmt.Fprintf(w, "%-24s%12s%12s%12s%12s\n", "variant", "mean hops", "inf", "sent avg", "reused avg")
+	for i, res := range cmp.Results {
+		fmt.Fprintf(w, "%-24s%12.3f%11.2f%%%12.1f%12.1f\n", cmp.Algorithms[i], res.MeanHops(),
+			percent(res.Infinite, res.Completed), cmp.MeanSent[i], res.MeanReused())
+	}
+
+	fmt.Fprintf(w, "Paired tests (a - b), '*' marks p < %.2f:\n", significance)
+	for _, p := range cmp.Pairs {
+		fmt.Fprintf(w, "%s vs %s\n", p.A, p.B)
+		fmt.Fprintf(w, "  filled: only a %d, only b %d, p=%.4g%s\n",
+			p.Filled.OnlyA, p.Filled.OnlyB, p.Filled.P, mark(p.Filled.P))
+		ttestLine(w, "hops", p.Hops)
+		ttestLine(w, "sent", p.Sent)
+		ttestLine(w, "reused", p.Reused)
+	}
+}
+
+func ttestLine(w io.Writer, name string, t stats.TTest) {
+	if t.N < 2 {
+		fmt.Fprintf(w, "  %s: not enough data\n", name)
+		return
+	}
+	fmt.Fprintf(w, "  %s: diff %.3f ± %.3f (n=%d, t=%.3f, p=%.4g)%s\n",
+		name, t.MeanDiff, t.StdErr, t.N, t.T, t.P, mark(t.P))
+}
+
+func mark(p float64) string {
+	if p < significance {
+		return " *"
+	}
+	return ""
+}
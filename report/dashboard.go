@@ -0,0 +1,90 @@
+package report
+
+import (
+	"fmt"
+	"gossipmodel/runner"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	refreshInterval = 200 * time.Millisecond
+	barWidth        = 40
+)
+
+type (
+	// Dashboard redraws experiment progress in the terminal
+	Dashboard struct {
+		out   io.Writer
+		lines int // number of lines drawn last time
+	}
+)
+
+func NewDashboard(out io.Writer) *Dashboard {
+	return &Dashboard{out: out}
+}
+
+// Watch redraws dashboard with runner progress until done is closed.
+func (d *Dashboard) Watch(r *runner.Runner, done <-chan struct{}) {
+	ticker := time.NewTicker(refreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			d.Draw(r.Progress())
+			return
+		case <-ticker.C:
+			d.Draw(r.Progress())
+		}
+	}
+}
+
+// Draw replaces previously drawn dashboard with the new state.
+func (d *Dashboard) Draw(res runner.Result) {
+	completed := res.Completed
+	total := res.Config.Experiments
+	elapsed := res.Elapsed
+
+	eta := "-"
+	if completed > 0 {
+		left := time.Duration(float64(elapsed) / float64(completed) * float64(total-completed))
+		eta = left.Round(time.Millisecond).String()
+	}
+
+	lines := []string{
+		fmt.Sprintf("Experiments: %d/%d (%.2f%%)  elapsed: %s  ETA: %s",
+			completed, total, percent(completed, total), elapsed.Round(time.Millisecond), eta),
+	}
+	if mean := res.MeanHops(); mean > 0 {
+		lines = append(lines, fmt.Sprintf("Mean hops: %.3f", mean))
+	} else {
+		lines = append(lines, "Mean hops: -")
+	}
+	for _, hop := range sortedHops(res) {
+		lines = append(lines, histogramLine(fmt.Sprint(hop), res.Hops[hop], completed))
+	}
+	lines = append(lines, histogramLine("inf", res.Infinite, completed))
+
+	if d.lines > 0 {
+		fmt.Fprintf(d.out, "\033[%dA", d.lines)
+	}
+	for _, line := range lines {
+		fmt.Fprintf(d.out, "\r\033[K%s\n", line)
+	}
+	// clean lines left from the previous, longer histogram
+	for i := len(lines); i < d.lines; i++ {
+		fmt.Fprint(d.out, "\r\033[K\n")
+	}
+	if len(lines) > d.lines {
+		d.lines = len(lines)
+	}
+}
+
+func histogramLine(label string, v, total int) string {
+	width := 0
+	if total > 0 {
+		width = v * barWidth / total
+	}
+	return fmt.Sprintf("%4s | %-*s %d (%.2f%%)", label, barWidth, strings.Repeat("#", width), v, percent(v, total))
+}
@@ -0,0 +1,40 @@
+package report
+
+import (
+	"fmt"
+	"gossipmodel/runner"
+	"gossipmodel/stats"
+	"io"
+	"sort"
+)
+
+// slowest is a number of nodes with the largest average latency to show.
+const slowest = 5
+
+// Latency writes distributions of hops when nodes got data: typical node,
+// the last node of experiment and nodes with the largest average latency.
+func Latency(w io.Writer, res runner.Result) {
+	fmt.Fprintf(w, "%-14s%10s%8s%8s%8s%8s\n", "hops to data", "mean", "p50", "p90", "p99", "max")
+	latencyLine(w, "node", res.Latency)
+	latencyLine(w, "last node", res.LastInformed)
+
+	nodes := make([]int, 0, len(res.NodeLatency))
+	for node := range res.NodeLatency {
+		nodes = append(nodes, node)
+	}
+	sort.SliceStable(nodes, func(i, j int) bool {
+		return res.NodeLatency[nodes[i]] > res.NodeLatency[nodes[j]]
+	})
+	if len(nodes) > slowest {
+		nodes = nodes[:slowest]
+	}
+	fmt.Fprint(w, "Slowest nodes (avg hops):")
+	for _, node := range nodes {
+		fmt.Fprintf(w, "  %d:%.3f", node, res.NodeLatency[node])
+	}
+	fmt.Fprintln(w)
+}
+
+func latencyLine(w io.Writer, name string, d stats.Distribution) {
+	fmt.Fprintf(w, "%-14s%10.3f%8d%8d%8d%8d\n", name, d.Mean, d.P50, d.P90, d.P99, d.Max)
+}
@@ -0,0 +1,21 @@
+package report
+
+import (
+	"fmt"
+	"gossipmodel/runner"
+	"gossipmodel/stats"
+	"io"
+)
+
+// Load writes distributions of messages sent, received and received as
+// duplicates by single node in single experiment.
+func Load(w io.Writer, res runner.Result) {
+	fmt.Fprintf(w, "%-12s%10s%8s%8s%8s%8s%8s\n", "per node", "mean", "max", "gini", "p50", "p90", "p99")
+	loadLine(w, "sent", res.Load.Sent)
+	loadLine(w, "received", res.Load.Received)
+	loadLine(w, "duplicates", res.Load.Duplicates)
+}
+
+func loadLine(w io.Writer, name string, d stats.Distribution) {
+	fmt.Fprintf(w, "%-12s%10.3f%8d%8.3f%8d%8d%8d\n", name, d.Mean, d.Max, d.Gini, d.P50, d.P90, d.P99)
+}
@@ -0,0 +1,29 @@
+package report
+
+import (
+	"fmt"
+	"gossipmodel/runner"
+	"io"
+)
+
+// Percolation writes share of outbreaks and coverage for each forwarding
+// probability of the scan and percolation threshold.
+func Percolation(w io.Writer, res runner.Percolation) {
+	if res.Canceled {
+		fmt.Fprintln(w, "Interrupted: scan results are partial")
+	}
+	fmt.Fprintf(w, "%12s%12s%12s%12s\n", "probability", "outbreak", "coverage", "sent avg")
+	for _, p := range res.Points {
+		sent := 0.0
+		if p.Result.Completed > 0 {
+			sent = float64(p.Result.Messages.Sent) / float64(p.Result.Completed)
+		}
+		fmt.Fprintf(w, "%12.3f%11.2f%%%11.2f%%%12.1f\n", p.Probability, p.Outbreak*100, p.Coverage*100, sent)
+	}
+	if res.Threshold > 0 {
+		fmt.Fprintf(w, "Threshold: p = %.3f", res.Threshold)
+	} else {
+		fmt.Fprint(w, "Threshold: not reached")
+	}
+	fmt.Fprintf(w, " (branching estimate 1/F = %.3f)\n", res.Critical)
+}
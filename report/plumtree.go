@@ -0,0 +1,34 @@
+package report
+
+import (
+	"fmt"
+	"gossipmodel/runner"
+	"io"
+)
+
+// Plumtree writes average messages of each broadcast of Plumtree and of
+// naive-once with the same config for comparison.
+func Plumtree(w io.Writer, res runner.PlumtreeResult) {
+	if res.Canceled {
+		fmt.Fprintf(w, "Interrupted: %d of %d experiments completed\n",
+			res.Completed, res.Config.Experiments)
+	}
+	fmt.Fprintf(w, "Size: %d Fan-out: %d Broadcasts: %d Graft timeout: %d",
+		res.Config.Size, res.Config.Fanout, res.Plumtree.Broadcasts, res.Plumtree.GraftTimeout)
+	if res.Plumtree.Crash > 0 {
+		fmt.Fprintf(w, " Crashed: %d before broadcast %d", res.Plumtree.Crash, res.CrashAt+1)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "%10s%10s%10s%10s%10s%10s%10s%10s%10s\n",
+		"broadcast", "data", "reused", "ihave", "graft", "prune", "lost", "coverage", "epochs")
+	for b, r := range res.Rounds {
+		plumtreeLine(w, fmt.Sprint(b+1), r)
+	}
+	plumtreeLine(w, "naive", res.Naive)
+	fmt.Fprintln(w, res.Elapsed)
+}
+
+func plumtreeLine(w io.Writer, name string, r runner.PlumtreeRound) {
+	fmt.Fprintf(w, "%10s%10.1f%10.1f%10.1f%10.1f%10.1f%10.1f%9.2f%%%10.2f\n",
+		name, r.Sent, r.Reused, r.IHave, r.Graft, r.Prune, r.Lost, r.Coverage*100, r.Epochs)
+}
@@ -0,0 +1,24 @@
+package report
+
+import (
+	"fmt"
+	"gossipmodel/runner"
+	"io"
+)
+
+// RareEvent writes importance sampling estimate of probability that
+// propagation does not fill the network.
+func RareEvent(w io.Writer, res runner.RareResult) {
+	if res.Canceled {
+		fmt.Fprintln(w, "Interrupted: estimate is based on completed samples")
+	}
+	cfg := res.Config
+	fmt.Fprintf(w, "Size: %d Fan-out: %d Samples: %d Seed: %d\n",
+		cfg.Size, cfg.Fanout, res.Samples, cfg.Seed)
+	fmt.Fprintf(w, "P(not filled) = %.4g ± %.2g\n", res.Probability, res.StdErr)
+	fmt.Fprintf(w, "95%% confidence interval: [%.4g, %.4g]\n", res.Lower, res.Upper)
+	if res.Probability > 0 {
+		fmt.Fprintf(w, "Relative error: %.2f%%\n", res.StdErr/res.Probability*100)
+	}
+	fmt.Fprintln(w, res.Elapsed)
+}
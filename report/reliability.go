@@ -0,0 +1,31 @@
+package report
+
+import (
+	"fmt"
+	"gossipmodel/runner"
+	"io"
+	"sort"
+)
+
+// Reliability writes partial coverage metrics of the run: atomic delivery,
+// share of experiments reached each coverage level (in percent) and
+// distribution of nodes without data.
+func Reliability(w io.Writer, res runner.Result, levels []float64) {
+	fmt.Fprintf(w, "Atomic delivery: %.4f%% (%d of %d)\n",
+		res.Atomic()*100, res.Missed[0], res.Completed)
+	for _, level := range levels {
+		fmt.Fprintf(w, "Coverage >= %g%%: %.4f%%\n", level, res.CoverageAtLeast(level)*100)
+	}
+	fmt.Fprintf(w, "Mean final coverage: %.4f%%\n", res.MeanFinalCoverage()*100)
+
+	missed := make([]int, 0, len(res.Missed))
+	for m := range res.Missed {
+		missed = append(missed, m)
+	}
+	sort.Ints(missed)
+	fmt.Fprint(w, "Uninformed nodes:")
+	for _, m := range missed {
+		fmt.Fprintf(w, "  %d:%d (%.2f%%)", m, res.Missed[m], percent(res.Missed[m], res.Completed))
+	}
+	fmt.Fprintln(w)
+}
@@ -0,0 +1,62 @@
+package report
+
+import (
+	"fmt"
+	"gossipmodel/model"
+	"io"
+	"sort"
+)
+
+const frameRowSize = 50 // number of node states in a row
+
+// Frame writes node states, chosen nodes and stat of the replay frame.
+// Frames are numbered from 0 for initial state up to last.
+func Frame(w io.Writer, f model.Frame, num int, last int) {
+	if f.Epoch < 0 {
+		fmt.Fprintf(w, "Frame %d/%d: initial state, coverage %d/%d\n",
+			num, last, f.Stat.Coverage, len(f.Topology))
+	} else {
+		fmt.Fprintf(w, "Frame %d/%d: epoch %d, sent %d, reused %d, coverage %d/%d\n",
+			num, last, f.Epoch+1, f.Stat.Sent, f.Stat.Reused, f.Stat.Coverage, len(f.Topology))
+	}
+
+	fmt.Fprintln(w, "States ('.' has no data, '*' ready to propagate, '#' propagated):")
+	for row := 0; row < len(f.Topology); row += frameRowSize {
+		fmt.Fprintf(w, "%6d  ", row)
+		for i := row; i < row+frameRowSize && i < len(f.Topology); i++ {
+			if i > row && (i-row)%10 == 0 {
+				fmt.Fprint(w, " ")
+			}
+			switch f.Topology[i] {
+			case 0:
+				fmt.Fprint(w, ".")
+			case 1:
+				fmt.Fprint(w, "*")
+			default:
+				fmt.Fprint(w, "#")
+			}
+		}
+		fmt.Fprintln(w)
+	}
+
+	if len(f.Sent) == 0 {
+		return
+	}
+	senders := make([]int, 0, len(f.Sent))
+	for id := range f.Sent {
+		senders = append(senders, id)
+	}
+	sort.Ints(senders)
+	fmt.Fprintln(w, "Sent:")
+	for _, id := range senders {
+		fmt.Fprintf(w, "  Node:#%d -> %v\n", id, f.Sent[id])
+	}
+}
+
+// Replay writes all frames of the experiment.
+func Replay(w io.Writer, frames []model.Frame) {
+	for i, f := range frames {
+		Frame(w, f, i, len(frames)-1)
+		fmt.Fprintln(w)
+	}
+}
@@ -0,0 +1,46 @@
+package report
+
+import (
+	"fmt"
+	"gossipmodel/runner"
+	"io"
+)
+
+// maxStalenessRows limits epochs of staleness table, epochs are sampled
+// evenly and the last one is always shown.
+const maxStalenessRows = 20
+
+// Reconcile writes bytes per exchange of reconciliation protocols and
+// staleness of entries over epochs.
+func Reconcile(w io.Writer, res runner.ReconcileResult) {
+	if res.Canceled {
+		fmt.Fprintf(w, "Interrupted: %d of %d experiments completed\n",
+			res.Completed, res.Config.Experiments)
+	}
+	rc := res.Reconcile
+	fmt.Fprintf(w, "Size: %d Fan-out: %d Epochs: %d Keys: %d Update rate: %.2f MTU: %d, updates stop after epoch %d\n",
+		res.Config.Size, res.Config.Fanout, rc.Epochs, rc.Keys, rc.UpdateRate, rc.MTU, rc.Epochs/2)
+	fmt.Fprintf(w, "%-14s%12s%10s%11s%11s\n", "protocol", "bytes/exch", "deltas", "redundant", "converged")
+	for _, p := range res.Protocols {
+		fmt.Fprintf(w, "%-14s%12.1f%10.2f%10.2f%%%10.2f%%\n",
+			p.Name, p.Bytes, p.Deltas, p.Redundant*100, p.Converged*100)
+	}
+
+	fmt.Fprintf(w, "%-8s", "epoch")
+	for _, p := range res.Protocols {
+		fmt.Fprintf(w, "%14s", p.Name)
+	}
+	fmt.Fprintln(w)
+	step := (rc.Epochs + maxStalenessRows - 1) / maxStalenessRows
+	for epoch := 0; epoch < rc.Epochs; epoch++ {
+		if (epoch+1)%step != 0 && epoch != rc.Epochs-1 {
+			continue
+		}
+		fmt.Fprintf(w, "%-8d", epoch+1)
+		for _, p := range res.Protocols {
+			fmt.Fprintf(w, "%13.3f%%", p.Staleness[epoch]*100)
+		}
+		fmt.Fprintln(w)
+	}
+	fmt.Fprintln(w, res.Elapsed)
+}
@@ -0,0 +1,92 @@
+package report
+
+import (
+	"fmt"
+	"gossipmodel/chart"
+	"gossipmodel/runner"
+	"io"
+	"strconv"
+)
+
+// SweepText writes table of sweep results: parameter values, mean hops
+// to fill the network, share of infinite experiments and mean final
+// coverage.
+func SweepText(w io.Writer, s runner.SweepResult) {
+	for _, a := range s.Axes {
+		fmt.Fprintf(w, "%12s", a.Param)
+	}
+	fmt.Fprintf(w, "%12s%12s%12s\n", "mean hops", "inf", "coverage")
+	for i, res := range s.Results {
+		for _, v := range sweepValues(s, i) {
+			fmt.Fprintf(w, "%12d", v)
+		}
+		if res.Completed == 0 {
+			fmt.Fprintf(w, "%12s%12s%12s\n", "-", "-", "-")
+			continue
+		}
+		fmt.Fprintf(w, "%12.3f%11.2f%%%11.2f%%\n", res.MeanHops(),
+			percent(res.Infinite, res.Completed), res.MeanFinalCoverage()*100)
+	}
+	if s.Canceled {
+		fmt.Fprintln(w, "Interrupted: sweep results are partial")
+	}
+}
+
+// SweepChart renders mean hops of the sweep: line chart for a single axis
+// and heatmap for two axes, where the first axis is drawn on y.
+func SweepChart(w io.Writer, s runner.SweepResult) error {
+	return sweepChart(w, s, "Mean hops to fill the network", "hops", runner.Result.MeanHops)
+}
+
+// SweepCoverageChart renders mean final coverage of the sweep in percent,
+// e.g. as a function of TTL and fan-out.
+func SweepCoverageChart(w io.Writer, s runner.SweepResult) error {
+	return sweepChart(w, s, "Mean final coverage, %", "coverage", func(res runner.Result) float64 {
+		return res.MeanFinalCoverage() * 100
+	})
+}
+
+func sweepChart(w io.Writer, s runner.SweepResult, title, ylabel string, value func(runner.Result) float64) error {
+	switch len(s.Axes) {
+	case 1:
+		xs := make([]string, 0, len(s.Results))
+		ys := make([]float64, 0, len(s.Results))
+		for i, res := range s.Results {
+			xs = append(xs, strconv.Itoa(s.Axes[0].Values[i]))
+			ys = append(ys, value(res))
+		}
+		return chart.Line(w, title, s.Axes[0].Param, ylabel, xs, ys)
+	case 2:
+		rows, cols := s.Axes[0].Values, s.Axes[1].Values
+		if len(s.Results) < len(rows)*len(cols) {
+			return chart.ErrEmptyData
+		}
+		xlabels := make([]string, len(cols))
+		for j, v := range cols {
+			xlabels[j] = strconv.Itoa(v)
+		}
+		ylabels := make([]string, len(rows))
+		values := make([][]float64, len(rows))
+		for i, v := range rows {
+			ylabels[i] = strconv.Itoa(v)
+			values[i] = make([]float64, len(cols))
+			for j := range cols {
+				values[i][j] = value(s.Results[i*len(cols)+j])
+			}
+		}
+		return chart.Heatmap(w, title,
+			s.Axes[1].Param, s.Axes[0].Param, xlabels, ylabels, values)
+	}
+	return chart.ErrEmptyData
+}
+
+// sweepValues returns axes values of i-th sweep result.
+func sweepValues(s runner.SweepResult, i int) []int {
+	values := make([]int, len(s.Axes))
+	for k := len(s.Axes) - 1; k >= 0; k-- {
+		n := len(s.Axes[k].Values)
+		values[k] = s.Axes[k].Values[i%n]
+		i /= n
+	}
+	return values
+}
@@ -0,0 +1,37 @@
+package report
+
+import (
+	"fmt"
+	"gossipmodel/runner"
+	"gossipmodel/stats"
+	"io"
+)
+
+// Swim writes messages, detection times of crashed nodes and rates of false
+// detections of SWIM experiments.
+func Swim(w io.Writer, res runner.SwimResult) {
+	if res.Canceled {
+		fmt.Fprintf(w, "Interrupted: %d of %d experiments completed\n",
+			res.Completed, res.Config.Experiments)
+	}
+	fmt.Fprintf(w, "Size: %d Periods: %d Crashed: %d Indirect probes: %d Suspicion timeout: %d Loss: %s %.2f%%\n",
+		res.Config.Size, res.Swim.Periods, res.Swim.Crash, res.Swim.IndirectProbes,
+		res.Swim.SuspicionTimeout, res.Swim.Loss, res.Swim.LossRate*100)
+	m := res.Messages
+	fmt.Fprintf(w, "Messages per node per period: ping %.3f, ping-req %.3f, ack %.3f, lost %.3f, updates %.3f\n",
+		m.Ping, m.PingReq, m.Ack, m.Lost, m.Updates)
+	if crashed := res.Swim.Crash * res.Completed; crashed > 0 {
+		fmt.Fprintf(w, "%-12s%10s%8s%8s%8s%8s\n", "periods", "mean", "max", "p50", "p90", "p99")
+		swimLine(w, "suspicion", res.Suspicion)
+		swimLine(w, "detection", res.Detection)
+		fmt.Fprintf(w, "Undetected: %d of %d crashed nodes (%.2f%%)\n",
+			res.Undetected, crashed, float64(res.Undetected)/float64(crashed)*100)
+	}
+	fmt.Fprintf(w, "False suspicions per node per period: %.3g\n", res.FalseSuspicions)
+	fmt.Fprintf(w, "False positives per node per period: %.3g\n", res.FalsePositives)
+	fmt.Fprintln(w, res.Elapsed)
+}
+
+func swimLine(w io.Writer, name string, d stats.Distribution) {
+	fmt.Fprintf(w, "%-12s%10.3f%8d%8d%8d%8d\n", name, d.Mean, d.Max, d.P50, d.P90, d.P99)
+}
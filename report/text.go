@@ -0,0 +1,75 @@
+package report
+
+/*
+	Reporters print runner results in different formats.
+*/
+
+import (
+	"fmt"
+	"gossipmodel/model"
+	"gossipmodel/runner"
+	"io"
+	"sort"
+)
+
+// Text writes human readable result of the run.
+func Text(w io.Writer, res runner.Result) {
+	if res.Canceled {
+		fmt.Fprintf(w, "Interrupted: %d of %d experiments completed\n",
+			res.Completed, res.Config.Experiments)
+	}
+	fmt.Fprintf(w, "Size: %d Fan-out: %d", res.Config.Size, res.Config.Fanout)
+	if res.Config.Algorithm != model.DefaultAlgorithm {
+		fmt.Fprintf(w, " Algorithm: %s", res.Config.Algorithm)
+	}
+	if m := res.Config.Membership; m != "" && m != model.GlobalMembership {
+		fmt.Fprintf(w, " Membership: %s", m)
+	}
+	if res.Config.Crash > 0 {
+		fmt.Fprintf(w, " Crashed: %d", res.Config.Crash)
+	}
+	fmt.Fprintln(w)
+	for _, hop := range sortedHops(res) {
+		fmt.Fprintf(w, "%d:%d (%.2f%%)  ", hop, res.Hops[hop], percent(res.Hops[hop], res.Completed))
+	}
+	fmt.Fprintf(w, "inf:%d (%.2f%%)\n", res.Infinite, percent(res.Infinite, res.Completed))
+	if res.Infinite > 0 {
+		fmt.Fprintf(w, "Stalled: %d (avg coverage %.1f of %d), epoch limit: %d\n",
+			res.Stalled, res.StalledCoverage, res.Config.Size-res.Config.Crash, res.Limited)
+	}
+	if res.Completed > 0 {
+		// reused messages are accounted in filled experiments only
+		fmt.Fprintf(w, "Reused avg: %.0f\n", res.MeanReused())
+		m, num := res.Messages, float64(res.Completed)
+		fmt.Fprintf(w, "Messages avg: sent %.1f, delivered %.1f, stale %.1f, concurrent %.1f, excluded %.1f\n",
+			float64(m.Sent)/num, float64(m.Delivered)/num, float64(m.Stale)/num,
+			float64(m.Concurrent)/num, float64(m.Excluded)/num)
+	}
+	fmt.Fprintln(w, res.Elapsed)
+}
+
+// CSV writes result of the run in a single semicolon separated line:
+// size, fan-out and number of experiments filled in 1..20 hops.
+func CSV(w io.Writer, res runner.Result) {
+	fmt.Fprintf(w, "%d;%d;", res.Config.Size, res.Config.Fanout)
+	for hop := 1; hop <= 20; hop++ {
+		fmt.Fprintf(w, "%d;", res.Hops[hop])
+	}
+	fmt.Fprintln(w)
+}
+
+func sortedHops(res runner.Result) []int {
+	hops := make([]int, 0, len(res.Hops))
+	for hop := range res.Hops {
+		hops = append(hops, hop)
+	}
+	sort.Ints(hops)
+	return hops
+}
+
+func percent(v, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(v) / float64(total) * 100
+}
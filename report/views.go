@@ -0,0 +1,19 @@
+package report
+
+import (
+	"fmt"
+	"gossipmodel/runner"
+	"io"
+)
+
+// Views writes in-degree distribution and clustering coefficient of
+// partial views of alive nodes.
+func Views(w io.Writer, res runner.Result) {
+	if res.Views.InDegree.Count == 0 {
+		fmt.Fprintln(w, "global membership: every node knows the whole network")
+		return
+	}
+	fmt.Fprintf(w, "%-12s%10s%8s%8s%8s%8s%8s\n", "views", "mean", "max", "gini", "p50", "p90", "p99")
+	loadLine(w, "in-degree", res.Views.InDegree)
+	fmt.Fprintf(w, "%-12s%10.4f\n", "clustering", res.Views.Clustering)
+}
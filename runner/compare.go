@@ -0,0 +1,197 @@
+package runner
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"gossipmodel/stats"
+	"strings"
+	"time"
+)
+
+type (
+	// Comparison contains results of several variants of config run on
+	// identical networks with identical random streams, and paired tests
+	// of them. Variant is an algorithm name or param=value override.
+	Comparison struct {
+		Algorithms []string     `json:"algorithms"` // variants
+		Results    []Result     `json:"results"`    // aggregated result of each algorithm
+		MeanSent   []float64    `json:"mean_sent"`  // average messages sent by each algorithm
+		Pairs      []PairedTest `json:"pairs"`      // tests of every pair of algorithms
+		Canceled   bool         `json:"canceled"`
+	}
+
+	// PairedTest compares two variants experiment by experiment. With
+	// antithetic experiments t-tests compare means of antithetic pairs.
+	PairedTest struct {
+		A      string        `json:"a"`
+		B      string        `json:"b"`
+		Filled stats.McNemar `json:"filled"` // share of experiments filled the network
+		Hops   stats.TTest   `json:"hops"`   // hops to fill, pairs where both filled
+		Sent   stats.TTest   `json:"sent"`   // messages sent
+		Reused stats.TTest   `json:"reused"` // redundant messages
+	}
+
+	// outcome is a compact result of single experiment.
+	outcome struct {
+		done   bool
+		filled bool
+		hops   int
+		sent   int
+		reused int
+	}
+)
+
+var (
+	ErrNotEnoughAlgorithms = errors.New("at least two algorithms are required")
+	ErrInvalidVariant      = errors.New("variants must not change number of experiments or antithetic mode")
+)
+
+// Compare runs experiments of base config with every variant: an algorithm
+// name or param=value override, e.g. "fanout=5". Experiment k of each
+// variant uses the same random seed (common random numbers), so differences
+// come from variants only. Random seed is chosen when base.Seed is zero.
+// When ctx is done, only experiments completed by all variants are compared.
+func Compare(ctx context.Context, base Config, algorithms []string) (Comparison, error) {
+	if len(algorithms) < 2 {
+		return Comparison{}, ErrNotEnoughAlgorithms
+	}
+	if base.Seed == 0 {
+		base.Seed = randomSeed()
+	}
+
+	runners := make([]*Runner, len(algorithms))
+	for i, variant := range algorithms {
+		cfg := base
+		if kv := strings.SplitN(variant, "=", 2); len(kv) == 2 {
+			if err := cfg.Set(kv[0], kv[1]); err != nil {
+				return Comparison{}, fmt.Errorf("%s: %v", kv[0], err)
+			}
+		} else {
+			cfg.Algorithm = variant
+		}
+		if cfg.Experiments != base.Experiments || cfg.Antithetic != base.Antithetic {
+			return Comparison{}, ErrInvalidVariant
+		}
+		r, err := New(cfg)
+		if err != nil {
+			return Comparison{}, err
+		}
+		runners[i] = r
+	}
+	base = runners[0].cfg
+
+	outcomes := make([][]outcome, len(algorithms))
+	for i := range outcomes {
+		outcomes[i] = make([]outcome, base.Experiments)
+	}
+
+	start := time.Now()
+	forEach(ctx, base.Workers, base.Experiments, func(k int) {
+		// each job writes only k-th outcomes, so no locks needed
+		for i, r := range runners {
+			e := Single(r.cfg.experiment(k))
+			r.add(e)
+			outcomes[i][k] = outcome{
+				done:   true,
+				filled: e.Filled,
+				hops:   e.Epochs + 1,
+				sent:   e.Sent,
+				reused: e.Reused,
+			}
+		}
+	})
+	finish := time.Now()
+
+	cmp := Comparison{Algorithms: algorithms}
+	for i, r := range runners {
+		r.start, r.finish = start, finish
+		res := r.Progress()
+		cmp.Canceled = cmp.Canceled || res.Canceled
+		cmp.Results = append(cmp.Results, res)
+
+		sent := make([]float64, 0, len(outcomes[i]))
+		for _, o := range outcomes[i] {
+			if o.done {
+				sent = append(sent, float64(o.sent))
+			}
+		}
+		cmp.MeanSent = append(cmp.MeanSent, stats.Mean(sent))
+	}
+	for a := 0; a < len(algorithms); a++ {
+		for b := a + 1; b < len(algorithms); b++ {
+			cmp.Pairs = append(cmp.Pairs, pairedTest(algorithms[a], algorithms[b], outcomes[a], outcomes[b], base.Antithetic))
+		}
+	}
+	return cmp, nil
+}
+
+func pairedTest(nameA, nameB string, a, b []outcome, antithetic bool) PairedTest {
+	res := PairedTest{A: nameA, B: nameB}
+
+	var (
+		filledA, filledB []bool
+		hopsA, hopsB     []float64
+		sentA, sentB     []float64
+		reusedA, reusedB []float64
+	)
+	for k := range a {
+		if !a[k].done || !b[k].done {
+			continue
+		}
+		filledA = append(filledA, a[k].filled)
+		filledB = append(filledB, b[k].filled)
+		sentA = append(sentA, float64(a[k].sent))
+		sentB = append(sentB, float64(b[k].sent))
+		reusedA = append(reusedA, float64(a[k].reused))
+		reusedB = append(reusedB, float64(b[k].reused))
+		if a[k].filled && b[k].filled {
+			hopsA = append(hopsA, float64(a[k].hops))
+			hopsB = append(hopsB, float64(b[k].hops))
+		}
+	}
+
+	// tests with not enough data keep zero values
+	res.Filled, _ = stats.McNemarTest(filledA, filledB)
+	if antithetic {
+		hopsA, hopsB = antitheticPairs(a, b, func(o outcome) (float64, bool) { return float64(o.hops), o.filled })
+		sentA, sentB = antitheticPairs(a, b, func(o outcome) (float64, bool) { return float64(o.sent), true })
+		reusedA, reusedB = antitheticPairs(a, b, func(o outcome) (float64, bool) { return float64(o.reused), true })
+	}
+	res.Hops, _ = stats.PairedTTest(hopsA, hopsB)
+	res.Sent, _ = stats.PairedTTest(sentA, sentB)
+	res.Reused, _ = stats.PairedTTest(reusedA, reusedB)
+	return res
+}
+
+// antitheticPairs returns means of value over antithetic pairs of experiments
+// 2m and 2m+1, for pairs where value is defined in all four experiments.
+func antitheticPairs(a, b []outcome, value func(outcome) (float64, bool)) (meansA, meansB []float64) {
+	for k := 0; k+1 < len(a); k += 2 {
+		if !a[k].done || !a[k+1].done || !b[k].done || !b[k+1].done {
+			continue
+		}
+		a0, ok0 := value(a[k])
+		a1, ok1 := value(a[k+1])
+		b0, ok2 := value(b[k])
+		b1, ok3 := value(b[k+1])
+		if ok0 && ok1 && ok2 && ok3 {
+			meansA = append(meansA, (a0+a1)/2)
+			meansB = append(meansB, (b0+b1)/2)
+		}
+	}
+	return meansA, meansB
+}
+
+// randomSeed returns non-zero random seed.
+func randomSeed() int64 {
+	var buf [8]byte
+	for {
+		rand.Read(buf[:])
+		if seed := int64(binary.BigEndian.Uint64(buf[:]) >> 1); seed != 0 {
+			return seed
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package runner
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompare(t *testing.T) {
+	cfg := Config{Size: 50, Fanout: 3, Experiments: 30, Seed: 1}
+
+	_, err := Compare(context.Background(), cfg, []string{"naive-once"})
+	require.Equal(t, ErrNotEnoughAlgorithms, err)
+
+	// identical algorithms with identical random streams have no difference
+	cmp, err := Compare(context.Background(), cfg, []string{"naive-once", "naive-once", "vector-once"})
+	require.NoError(t, err)
+	require.Len(t, cmp.Results, 3)
+	require.Len(t, cmp.Pairs, 3)
+	require.Equal(t, cmp.Results[0].Hops, cmp.Results[1].Hops)
+	require.Equal(t, 0, cmp.Pairs[0].Filled.OnlyA+cmp.Pairs[0].Filled.OnlyB)
+	require.Equal(t, 0.0, cmp.Pairs[0].Sent.MeanDiff)
+	require.Equal(t, 1.0, cmp.Pairs[0].Sent.P)
+	require.Equal(t, 30, cmp.Pairs[2].Sent.N)
+}
+
+func TestCompare_Variants(t *testing.T) {
+	cfg := Config{Size: 50, Fanout: 3, Experiments: 30, Seed: 1, Antithetic: true}
+
+	_, err := Compare(context.Background(), cfg, []string{"fanout=3", "experiments=10"})
+	require.Equal(t, ErrInvalidVariant, err)
+	_, err = Compare(context.Background(), cfg, []string{"fanout=3", "fanout=x"})
+	require.Error(t, err)
+
+	cmp, err := Compare(context.Background(), cfg, []string{"fanout=3", "fanout=3", "fanout=6"})
+	require.NoError(t, err)
+	require.Equal(t, 6, cmp.Results[2].Config.Fanout)
+	require.Equal(t, 0.0, cmp.Pairs[0].Sent.MeanDiff)
+	// t-tests use means of antithetic pairs
+	require.Equal(t, 15, cmp.Pairs[1].Sent.N)
+	require.True(t, cmp.Pairs[1].Sent.MeanDiff < 0)
+}
@@ -0,0 +1,158 @@
+package runner
+
+import (
+	"errors"
+	"gossipmodel/model"
+	"strconv"
+)
+
+// Params are names of config parameters which can be set by name.
+var Params = []string{"size", "fanout", "experiments", "node", "algorithm", "workers", "seed", "antithetic", "max-epochs", "probability", "forward-hops", "low-probability", "low-degree", "ttl", "membership", "view-size", "crash"}
+
+var (
+	ErrUnknownParam = errors.New("unknown parameter")
+)
+
+// DefaultConfig returns config with default parameters of the model.
+func DefaultConfig() Config {
+	return Config{
+		Size:        100,
+		Fanout:      10,
+		Experiments: 10,
+		Algorithm:   model.DefaultAlgorithm,
+		Membership:  model.GlobalMembership,
+		ForwardHops: 1,
+	}
+}
+
+// Set parses value and sets config parameter by its name.
+func (c *Config) Set(param, value string) error {
+	if param == "algorithm" {
+		if _, err := model.GetAlgorithm(value); err != nil {
+			return err
+		}
+		c.Algorithm = value
+		return nil
+	}
+
+	if param == "membership" {
+		if err := model.ValidMembership(value); err != nil {
+			return err
+		}
+		c.Membership = value
+		return nil
+	}
+
+	if param == "seed" {
+		seed, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return err
+		}
+		c.Seed = seed
+		return nil
+	}
+
+	if param == "antithetic" {
+		v, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		c.Antithetic = v
+		return nil
+	}
+
+	if param == "probability" || param == "low-probability" {
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return err
+		}
+		if param == "probability" {
+			c.Probability = v
+		} else {
+			c.LowProbability = v
+		}
+		return nil
+	}
+
+	v, err := strconv.Atoi(value)
+	if err != nil {
+		return err
+	}
+	switch param {
+	case "size":
+		c.Size = v
+	case "fanout":
+		c.Fanout = v
+	case "experiments":
+		c.Experiments = v
+	case "node":
+		c.InitialNode = v
+	case "workers":
+		c.Workers = v
+	case "max-epochs":
+		c.MaxEpochs = v
+	case "forward-hops":
+		c.ForwardHops = v
+	case "low-degree":
+		c.LowDegree = v
+	case "ttl":
+		c.TTL = v
+	case "crash":
+		c.Crash = v
+	case "view-size":
+		c.ViewSize = v
+	default:
+		return ErrUnknownParam
+	}
+	return nil
+}
+
+// Get returns config parameter by its name.
+func (c Config) Get(param string) (string, error) {
+	switch param {
+	case "size":
+		return strconv.Itoa(c.Size), nil
+	case "fanout":
+		return strconv.Itoa(c.Fanout), nil
+	case "experiments":
+		return strconv.Itoa(c.Experiments), nil
+	case "node":
+		return strconv.Itoa(c.InitialNode), nil
+	case "workers":
+		return strconv.Itoa(c.Workers), nil
+	case "max-epochs":
+		return strconv.Itoa(c.MaxEpochs), nil
+	case "probability":
+		return strconv.FormatFloat(c.Probability, 'g', -1, 64), nil
+	case "forward-hops":
+		return strconv.Itoa(c.ForwardHops), nil
+	case "low-probability":
+		return strconv.FormatFloat(c.LowProbability, 'g', -1, 64), nil
+	case "low-degree":
+		return strconv.Itoa(c.LowDegree), nil
+	case "ttl":
+		return strconv.Itoa(c.TTL), nil
+	case "crash":
+		return strconv.Itoa(c.Crash), nil
+	case "view-size":
+		return strconv.Itoa(c.ViewSize), nil
+	case "membership":
+		return c.Membership, nil
+	case "algorithm":
+		return c.Algorithm, nil
+	case "seed":
+		return strconv.FormatInt(c.Seed, 10), nil
+	case "antithetic":
+		return strconv.FormatBool(c.Antithetic), nil
+	}
+	return "", ErrUnknownParam
+}
+
+// Reset sets config parameter to its default value.
+func (c *Config) Reset(param string) error {
+	v, err := DefaultConfig().Get(param)
+	if err != nil {
+		return err
+	}
+	return c.Set(param, v)
+}
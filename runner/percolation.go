@@ -0,0 +1,92 @@
+package runner
+
+import (
+	"context"
+	"errors"
+)
+
+type (
+	// PercolationPoint is a result of probabilistic algorithm with single
+	// forwarding probability.
+	PercolationPoint struct {
+		Probability float64 `json:"probability"`
+		Outbreak    float64 `json:"outbreak"` // share of experiments delivered data to at least half of nodes
+		Coverage    float64 `json:"coverage"` // mean final share of nodes with data
+		Result      Result  `json:"result"`
+	}
+
+	// Percolation is a scan of forwarding probabilities. Probabilistic
+	// gossip is bimodal: below the threshold data dies out near the
+	// source, above it almost all nodes get data.
+	Percolation struct {
+		Points []PercolationPoint `json:"points"`
+
+		// Threshold is the probability where half of experiments reach
+		// at least half of nodes, interpolated between points, zero if
+		// there is no such probability.
+		Threshold float64 `json:"threshold"`
+
+		// Critical is the threshold of branching process approximation:
+		// sender has F*p informed children on average, so p = 1/F.
+		Critical float64 `json:"critical"`
+		Canceled bool    `json:"canceled"`
+	}
+)
+
+var (
+	ErrNotProbabilistic = errors.New("percolation scan requires probabilistic algorithm: gossip1, gossip2 or gossip-edge")
+)
+
+// probabilistic are algorithms which use forwarding probability.
+var probabilistic = map[string]bool{"gossip1": true, "gossip2": true, "gossip-edge": true}
+
+// PercolationScan runs experiments of base config with forwarding
+// probabilities 1/steps, 2/steps, ..., 1 with common random numbers and
+// finds percolation threshold. When ctx is done, the rest of probabilities
+// is not run.
+func PercolationScan(ctx context.Context, base Config, steps int) (Percolation, error) {
+	if !probabilistic[base.Algorithm] {
+		return Percolation{}, ErrNotProbabilistic
+	}
+	if steps <= 0 {
+		return Percolation{}, ErrInvalidAxis
+	}
+	if err := base.Validate(); err != nil {
+		return Percolation{}, err
+	}
+	if base.Seed == 0 {
+		base.Seed = randomSeed()
+	}
+
+	res := Percolation{Critical: 1 / float64(base.Fanout)}
+	for i := 1; i <= steps; i++ {
+		if ctx.Err() != nil {
+			res.Canceled = true
+			break
+		}
+		cfg := base
+		cfg.Probability = float64(i) / float64(steps)
+		r, err := New(cfg)
+		if err != nil {
+			return res, err
+		}
+		point := PercolationPoint{Probability: cfg.Probability, Result: r.Run(ctx)}
+		point.Outbreak = point.Result.CoverageAtLeast(50)
+		point.Coverage = point.Result.MeanFinalCoverage()
+		res.Canceled = res.Canceled || point.Result.Canceled
+		res.Points = append(res.Points, point)
+	}
+
+	for i, p := range res.Points {
+		if p.Outbreak < 0.5 {
+			continue
+		}
+		res.Threshold = p.Probability
+		if i > 0 {
+			prev := res.Points[i-1]
+			res.Threshold = prev.Probability + (0.5-prev.Outbreak)/(p.Outbreak-prev.Outbreak)*(p.Probability-prev.Probability)
+		}
+		break
+	}
+	return res, nil
+}
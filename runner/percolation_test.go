@@ -0,0 +1,30 @@
+package runner
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPercolationScan(t *testing.T) {
+	cfg := Config{Size: 200, Fanout: 4, Experiments: 50, Seed: 1, ForwardHops: 1}
+	_, err := PercolationScan(context.Background(), cfg, 10)
+	require.Equal(t, ErrNotProbabilistic, err)
+
+	cfg.Algorithm = "gossip1"
+	_, err = PercolationScan(context.Background(), cfg, 0)
+	require.Equal(t, ErrInvalidAxis, err)
+
+	res, err := PercolationScan(context.Background(), cfg, 10)
+	require.NoError(t, err)
+	require.Len(t, res.Points, 10)
+	require.Equal(t, 0.25, res.Critical)
+	require.Equal(t, 1.0, res.Points[9].Probability)
+	require.Equal(t, 0.0, res.Points[0].Outbreak)
+	require.Equal(t, 1.0, res.Points[9].Outbreak)
+	require.True(t, res.Threshold > 0.1 && res.Threshold < 1)
+
+	_, err = New(Config{Size: 10, Fanout: 2, Experiments: 1, Probability: 1.5})
+	require.Equal(t, ErrInvalidProbability, err)
+}
@@ -0,0 +1,195 @@
+package runner
+
+/*
+	Plumtree experiment is a sequence of broadcasts from random alive nodes
+	over the same overlay, see model.Plumtree. The first broadcast floods
+	the overlay and prunes it to a tree, so message overhead of next
+	broadcasts drops. Nodes may crash in the middle of the sequence to show
+	repair of the tree. Rounds are compared with naive-once experiments of
+	the same size and fan-out, where the same number of nodes crash before
+	propagation.
+*/
+
+import (
+	"context"
+	"errors"
+	"gossipmodel/model"
+	"sync"
+	"time"
+)
+
+type (
+	// PlumtreeRound is an average of broadcasts with the same index over
+	// experiments, messages are per broadcast.
+	PlumtreeRound struct {
+		Sent     float64 `json:"sent"`     // data messages
+		Reused   float64 `json:"reused"`   // data messages to nodes which already had data
+		IHave    float64 `json:"ihave"`    // IHAVE announcements
+		Graft    float64 `json:"graft"`    // GRAFT requests
+		Prune    float64 `json:"prune"`    // PRUNE replies
+		Lost     float64 `json:"lost"`     // messages to crashed peers
+		Alive    float64 `json:"alive"`    // nodes which did not crash
+		Coverage float64 `json:"coverage"` // share of alive nodes got data
+		Filled   float64 `json:"filled"`   // share of broadcasts delivered data to all alive nodes
+		Epochs   float64 `json:"epochs"`   // epochs until the last node got data
+	}
+
+	// PlumtreeConfig describes sequence of broadcasts of Plumtree
+	// experiment.
+	PlumtreeConfig struct {
+		Broadcasts int `json:"broadcasts"` // number of broadcasts of experiment
+		Crash      int `json:"crash"`      // number of nodes crashed before the middle broadcast
+
+		// GraftTimeout is a number of epochs node waits for data after
+		// IHAVE, zero stands for model.DefaultGraftTimeout.
+		GraftTimeout int `json:"graft_timeout"`
+	}
+
+	PlumtreeResult struct {
+		Config    Config          `json:"config"`
+		Plumtree  PlumtreeConfig  `json:"plumtree"`
+		CrashAt   int             `json:"crash_at"` // index of broadcast nodes crashed before
+		Rounds    []PlumtreeRound `json:"rounds"`
+		Completed int             `json:"completed"` // number of finished experiments
+		Elapsed   time.Duration   `json:"elapsed"`
+		Canceled  bool            `json:"canceled"`
+
+		// Naive is an average naive-once experiment of the same config.
+		Naive PlumtreeRound `json:"naive"`
+	}
+)
+
+var (
+	ErrInvalidBroadcasts = errors.New("number of broadcasts must be greater than zero")
+	ErrInvalidTimeout    = errors.New("graft timeout must not be negative")
+)
+
+// Plumtree runs cfg.Experiments sequences of broadcasts over Plumtree with
+// fan-out cfg.Fanout. When ctx is done, rounds are averaged over completed
+// experiments.
+func Plumtree(ctx context.Context, cfg Config, pcfg PlumtreeConfig) (PlumtreeResult, error) {
+	switch {
+	case pcfg.Broadcasts <= 0:
+		return PlumtreeResult{}, ErrInvalidBroadcasts
+	case pcfg.Crash < 0 || pcfg.Crash >= cfg.Size-1:
+		return PlumtreeResult{}, ErrInvalidCrash
+	case pcfg.GraftTimeout < 0:
+		return PlumtreeResult{}, ErrInvalidTimeout
+	}
+	if pcfg.GraftTimeout == 0 {
+		pcfg.GraftTimeout = model.DefaultGraftTimeout
+	}
+	// naive-once baseline uses global membership, its nodes crash
+	// before propagation
+	cfg.Algorithm = model.DefaultAlgorithm
+	cfg.Membership, cfg.Crash = "", pcfg.Crash
+	r, err := New(cfg)
+	if err != nil {
+		return PlumtreeResult{}, err
+	}
+	cfg = r.cfg
+	res := PlumtreeResult{
+		Config:   cfg,
+		Plumtree: pcfg,
+		CrashAt:  pcfg.Broadcasts / 2,
+		Rounds:   make([]PlumtreeRound, pcfg.Broadcasts),
+	}
+
+	start := time.Now()
+	mu := new(sync.Mutex)
+	forEach(ctx, cfg.Workers, cfg.Experiments, func(k int) {
+		rounds := plumtreeExperiment(cfg.experiment(k), pcfg, res.CrashAt)
+		mu.Lock()
+		for b, round := range rounds {
+			res.Rounds[b].add(round)
+		}
+		res.Completed++
+		mu.Unlock()
+	})
+	res.Canceled = res.Completed < cfg.Experiments
+	for b := range res.Rounds {
+		res.Rounds[b].scale(res.Completed)
+	}
+
+	naive := r.Run(ctx)
+	res.Canceled = res.Canceled || naive.Canceled
+	res.Elapsed = time.Since(start)
+	if naive.Completed > 0 {
+		num := float64(naive.Completed)
+		res.Naive = PlumtreeRound{
+			Sent:     float64(naive.Messages.Sent) / num,
+			Reused:   float64(naive.Messages.Reused) / num,
+			Alive:    float64(cfg.alive()),
+			Coverage: naive.MeanFinalCoverage(),
+			Filled:   float64(naive.Completed-naive.Infinite) / num,
+			Epochs:   naive.LastInformed.Mean,
+		}
+	}
+	return res, nil
+}
+
+// plumtreeExperiment runs broadcasts over new overlay of the config and
+// crashes nodes before broadcast crashAt.
+func plumtreeExperiment(cfg Config, pcfg PlumtreeConfig, crashAt int) []PlumtreeRound {
+	netmap, err := model.SampleNetwork(cfg.Size)
+	if err != nil {
+		panic(err)
+	}
+	seed(&netmap, cfg)
+	tree := model.NewPlumtree(&netmap, cfg.Fanout)
+	tree.SetGraftTimeout(pcfg.GraftTimeout)
+
+	rounds := make([]PlumtreeRound, pcfg.Broadcasts)
+	for b := range rounds {
+		if b == crashAt {
+			tree.CrashRandom(pcfg.Crash)
+		}
+		s := tree.Broadcast(tree.RandomAlive())
+		rounds[b] = PlumtreeRound{
+			Sent:     float64(s.Sent),
+			Reused:   float64(s.Reused),
+			IHave:    float64(s.IHave),
+			Graft:    float64(s.Graft),
+			Prune:    float64(s.Prune),
+			Lost:     float64(s.Lost),
+			Alive:    float64(tree.Alive()),
+			Coverage: float64(s.Coverage) / float64(tree.Alive()),
+			Epochs:   float64(s.Epochs),
+		}
+		if s.Coverage == tree.Alive() {
+			rounds[b].Filled = 1
+		}
+	}
+	return rounds
+}
+
+func (r *PlumtreeRound) add(o PlumtreeRound) {
+	r.Sent += o.Sent
+	r.Reused += o.Reused
+	r.IHave += o.IHave
+	r.Graft += o.Graft
+	r.Prune += o.Prune
+	r.Lost += o.Lost
+	r.Alive += o.Alive
+	r.Coverage += o.Coverage
+	r.Filled += o.Filled
+	r.Epochs += o.Epochs
+}
+
+// scale turns sums of num broadcasts into averages.
+func (r *PlumtreeRound) scale(num int) {
+	if num == 0 {
+		return
+	}
+	k := 1 / float64(num)
+	r.Sent *= k
+	r.Reused *= k
+	r.IHave *= k
+	r.Graft *= k
+	r.Prune *= k
+	r.Lost *= k
+	r.Alive *= k
+	r.Coverage *= k
+	r.Filled *= k
+	r.Epochs *= k
+}
@@ -0,0 +1,53 @@
+package runner
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlumtree(t *testing.T) {
+	cfg := Config{Size: 200, Fanout: 4, Experiments: 10, Seed: 1}
+	_, err := Plumtree(context.Background(), cfg, PlumtreeConfig{})
+	require.Equal(t, ErrInvalidBroadcasts, err)
+
+	res, err := Plumtree(context.Background(), cfg, PlumtreeConfig{Broadcasts: 4, Crash: 20})
+	require.NoError(t, err)
+	require.Equal(t, 10, res.Completed)
+	require.Equal(t, 2, res.CrashAt)
+	require.Equal(t, 0.0, res.Rounds[0].Lost)
+	require.True(t, res.Rounds[2].Lost > 0)
+
+	// filled broadcast delivers data once to every alive node except the
+	// source, other data messages are duplicates
+	for _, r := range res.Rounds {
+		require.Equal(t, 1.0, r.Filled)
+		require.InDelta(t, r.Alive-1, r.Sent-r.Reused, 1e-9)
+	}
+
+	// naive-once baseline has the same nodes alive as broadcasts after crash
+	require.Equal(t, 200.0, res.Rounds[0].Alive)
+	require.Equal(t, 180.0, res.Rounds[3].Alive)
+	require.Equal(t, res.Rounds[3].Alive, res.Naive.Alive)
+	require.True(t, res.Rounds[3].Sent < res.Naive.Sent/2)
+}
+
+func TestPlumtree_Settled(t *testing.T) {
+	cfg := Config{Size: 200, Fanout: 4, Experiments: 10, Seed: 1}
+
+	// the first broadcast prunes the overlay to spanning tree, long graft
+	// timeout does not graft shortcuts, so next broadcasts send data over
+	// eager links of the tree and IHAVE over the same lazy links
+	res, err := Plumtree(context.Background(), cfg, PlumtreeConfig{Broadcasts: 4, GraftTimeout: 100})
+	require.NoError(t, err)
+	require.True(t, res.Rounds[0].Prune > 0)
+	for _, r := range res.Rounds[1:] {
+		require.Equal(t, 199.0, r.Sent)
+		require.Equal(t, 0.0, r.Reused)
+		require.Equal(t, 0.0, r.Prune)
+		require.Equal(t, 0.0, r.Graft)
+		require.True(t, r.IHave > 0)
+		require.InDelta(t, res.Rounds[1].IHave, r.IHave, 1e-9)
+	}
+}
@@ -0,0 +1,121 @@
+package runner
+
+/*
+	Rare event estimation of probability that naive-once propagation does
+	not fill the network. Plain Monte Carlo needs about 100/P experiments
+	to estimate probability P, so importance sampling is used instead.
+
+	Each experiment isolates random node j (except initial one): j is
+	excluded from choices of every sender, so every experiment fails.
+	Sender of naive-once chooses fan-out F of N-1 other nodes, so it misses
+	j with probability 1-F/(N-1). Sampling from mixture of such processes
+	over all M=N-1 candidate nodes gives likelihood ratio
+
+		w = M * (1-F/(N-1))^S / K
+
+	where S is a number of senders (nodes sent data, see Network.History)
+	and K is a number of nodes without data. Mean of w is unbiased estimate
+	of the probability. Nodes stopped by TTL and nodes which did not send
+	before epoch limit are not senders, so the estimate is a probability
+	that network is not filled within TTL and epoch limit.
+*/
+
+import (
+	"context"
+	"errors"
+	"gossipmodel/model"
+	"gossipmodel/stats"
+	"math"
+	"time"
+)
+
+type (
+	// RareResult is an importance sampling estimate of probability that
+	// propagation does not fill the network.
+	RareResult struct {
+		Config      Config        `json:"config"`
+		Samples     int           `json:"samples"`     // number of completed experiments
+		Probability float64       `json:"probability"` // estimated probability of failure
+		StdErr      float64       `json:"std_err"`     // standard error of the estimate
+		Lower       float64       `json:"lower"`       // lower bound of 95% confidence interval
+		Upper       float64       `json:"upper"`       // upper bound of 95% confidence interval
+		Elapsed     time.Duration `json:"elapsed"`
+		Canceled    bool          `json:"canceled"`
+	}
+)
+
+const rareAlgorithm = "naive-once"
+
+var (
+	ErrRareAlgorithm  = errors.New("rare event estimation supports " + rareAlgorithm + " algorithm only")
+	ErrRareMembership = errors.New("rare event estimation supports global membership without crashes only")
+)
+
+// z is a quantile of standard normal distribution for 95% confidence.
+const z = 1.959964
+
+// RareEvent estimates probability that experiment of the config does not
+// fill the network, with cfg.Experiments importance samples. When ctx is
+// done, estimate is based on completed samples.
+func RareEvent(ctx context.Context, cfg Config) (RareResult, error) {
+	if cfg.Algorithm == "" {
+		cfg.Algorithm = rareAlgorithm
+	}
+	if cfg.Algorithm != rareAlgorithm {
+		return RareResult{}, ErrRareAlgorithm
+	}
+	if cfg.Membership != "" && cfg.Membership != model.GlobalMembership || cfg.Crash > 0 {
+		return RareResult{}, ErrRareMembership
+	}
+	r, err := New(cfg)
+	if err != nil {
+		return RareResult{}, err
+	}
+	cfg = r.cfg
+	res := RareResult{Config: cfg}
+	if cfg.Fanout >= cfg.Size-1 {
+		// every sender reaches all other nodes
+		res.Samples = cfg.Experiments
+		return res, nil
+	}
+
+	weights := make([]float64, cfg.Experiments)
+	done := make([]bool, cfg.Experiments)
+	start := time.Now()
+	forEach(ctx, cfg.Workers, cfg.Experiments, func(k int) {
+		e := cfg.experiment(k)
+		e.isolate = true
+		// each job writes only k-th weight, so no locks needed
+		weights[k] = rareWeight(cfg, Single(e))
+		done[k] = true
+	})
+	res.Elapsed = time.Since(start)
+
+	sample := make([]float64, 0, len(weights))
+	for k, w := range weights {
+		if done[k] {
+			sample = append(sample, w)
+		}
+	}
+	res.Samples = len(sample)
+	res.Canceled = res.Samples < cfg.Experiments
+	res.Probability = stats.Mean(sample)
+	if res.Samples > 1 {
+		res.StdErr = stats.StdDev(sample) / math.Sqrt(float64(res.Samples))
+	}
+	res.Lower = math.Max(0, res.Probability-z*res.StdErr)
+	res.Upper = math.Min(1, res.Probability+z*res.StdErr)
+	return res, nil
+}
+
+// rareWeight returns likelihood ratio of experiment with isolated node.
+func rareWeight(cfg Config, e Experiment) float64 {
+	senders := 0
+	for _, epoch := range e.Network.History {
+		senders += len(epoch)
+	}
+	missed := cfg.Size - e.Network.CountCoverage()
+	candidates := float64(cfg.Size - 1)
+	miss := math.Log1p(-float64(cfg.Fanout) / candidates)
+	return math.Exp(math.Log(candidates) + float64(senders)*miss - math.Log(float64(missed)))
+}
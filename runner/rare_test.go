@@ -0,0 +1,47 @@
+package runner
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRareEvent(t *testing.T) {
+	_, err := RareEvent(context.Background(), Config{Size: 10, Fanout: 2, Experiments: 10, Algorithm: "vector-once"})
+	require.Equal(t, ErrRareAlgorithm, err)
+
+	res, err := RareEvent(context.Background(), Config{Size: 10, Fanout: 9, Experiments: 10})
+	require.NoError(t, err)
+	require.Equal(t, 0.0, res.Probability)
+
+	// estimate agrees with plain Monte Carlo where failures are frequent
+	cfg := Config{Size: 10, Fanout: 2, Experiments: 20000, Seed: 1}
+	res, err = RareEvent(context.Background(), cfg)
+	require.NoError(t, err)
+	require.Equal(t, 20000, res.Samples)
+	require.True(t, res.Lower <= res.Probability && res.Probability <= res.Upper)
+
+	r, err := New(cfg)
+	require.NoError(t, err)
+	mc := r.Run(context.Background())
+	p := float64(mc.Infinite) / float64(mc.Completed)
+	se := math.Sqrt(res.StdErr*res.StdErr + p*(1-p)/float64(mc.Completed))
+	require.InDelta(t, p, res.Probability, 4*se)
+
+	// nodes stopped by TTL or epoch limit do not send
+	for _, cfg := range []Config{
+		{Size: 10, Fanout: 2, Experiments: 20000, Seed: 1, TTL: 3},
+		{Size: 10, Fanout: 2, Experiments: 20000, Seed: 1, MaxEpochs: 3},
+	} {
+		res, err = RareEvent(context.Background(), cfg)
+		require.NoError(t, err)
+		r, err := New(cfg)
+		require.NoError(t, err)
+		mc := r.Run(context.Background())
+		p := float64(mc.Infinite) / float64(mc.Completed)
+		se := math.Sqrt(res.StdErr*res.StdErr + p*(1-p)/float64(mc.Completed))
+		require.InDelta(t, p, res.Probability, 4*se, "%+v", cfg)
+	}
+}
@@ -0,0 +1,47 @@
+package runner
+
+import "math"
+
+// Atomic returns share of experiments which delivered data to all alive
+// nodes.
+func (r Result) Atomic() float64 {
+	if r.Completed == 0 {
+		return 0
+	}
+	return float64(r.Missed[0]) / float64(r.Completed)
+}
+
+// CoverageAtLeast returns share of experiments which delivered data to at
+// least given percent of alive nodes.
+func (r Result) CoverageAtLeast(percent float64) float64 {
+	if r.Completed == 0 {
+		return 0
+	}
+	// nodes allowed to miss data, small epsilon keeps exact levels like 99%
+	allowed := int(math.Floor(float64(r.Config.alive())*(100-percent)/100 + 1e-9))
+	ok := 0
+	for missed, v := range r.Missed {
+		if missed <= allowed {
+			ok += v
+		}
+	}
+	return float64(ok) / float64(r.Completed)
+}
+
+// MeanFinalCoverage returns average share of alive nodes with data at the
+// end of experiment.
+func (r Result) MeanFinalCoverage() float64 {
+	if r.Completed == 0 || r.Config.alive() <= 0 {
+		return 0
+	}
+	missed := 0
+	for m, v := range r.Missed {
+		missed += m * v
+	}
+	return 1 - float64(missed)/float64(r.Completed*r.Config.alive())
+}
+
+// alive returns number of nodes which do not crash.
+func (c Config) alive() int {
+	return c.Size - c.Crash
+}
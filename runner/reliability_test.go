@@ -0,0 +1,31 @@
+package runner
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestResult_Reliability(t *testing.T) {
+	res := Result{
+		Config:    Config{Size: 100},
+		Completed: 10,
+		Missed:    map[int]int{0: 5, 1: 3, 5: 2},
+	}
+	require.Equal(t, 0.5, res.Atomic())
+	require.Equal(t, 0.8, res.CoverageAtLeast(99))
+	require.Equal(t, 1.0, res.CoverageAtLeast(95))
+	require.Equal(t, 0.5, res.CoverageAtLeast(100))
+	require.InDelta(t, 1-13.0/1000, res.MeanFinalCoverage(), 1e-12)
+
+	r, err := New(Config{Size: 50, Fanout: 2, Experiments: 100, Seed: 1})
+	require.NoError(t, err)
+	res = r.Run(context.Background())
+	total := 0
+	for _, v := range res.Missed {
+		total += v
+	}
+	require.Equal(t, res.Completed, total)
+	require.Equal(t, res.Completed-res.Infinite, res.Missed[0])
+}
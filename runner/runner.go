@@ -0,0 +1,515 @@
+package runner
+
+/*
+	Runner executes push-gossip experiments concurrently and aggregates
+	their results. It is the library API of the model: main package and
+	other tools configure experiments with Config and get Result back.
+*/
+
+import (
+	"context"
+	"errors"
+	"gossipmodel/model"
+	"gossipmodel/stats"
+	"math/rand"
+	"runtime"
+	"sync"
+	"time"
+)
+
+type (
+	Config struct {
+		Size        int `json:"size"`         // size of network map
+		Fanout      int `json:"fanout"`       // size of fanout value
+		Experiments int `json:"experiments"`  // number of experiments
+		InitialNode int `json:"initial_node"` // index of leader node
+		Workers     int `json:"workers"`      // number of concurrent workers, 0 for default
+
+		// Algorithm is a name of propagation algorithm, see model.AlgorithmNames.
+		Algorithm string `json:"algorithm"`
+
+		// MaxEpochs limits number of epochs of single experiment, zero
+		// stands for network size. Algorithms which propagate forever
+		// stop at the limit only.
+		MaxEpochs int `json:"max_epochs"`
+
+		// Probability of forwarding and number of the first hops which
+		// always forward for probabilistic algorithms (gossip1, gossip2,
+		// gossip-edge). Zero probability stands for 1.
+		Probability float64 `json:"probability"`
+		ForwardHops int     `json:"forward_hops"`
+
+		// LowProbability is a probability of forwarding of gossip2 nodes
+		// with less than LowDegree neighbors. Zero stands for 1.
+		LowProbability float64 `json:"low_probability"`
+		LowDegree      int     `json:"low_degree"`
+
+		// TTL limits number of hops data travels, nodes do not forward
+		// data they got in TTL hops. Zero stands for no limit.
+		TTL int `json:"ttl"`
+
+		// Seed of random streams: experiment k uses source seeded with
+		// Seed+k, so runs are reproducible. Zero seed stands for crypto
+		// random source of each experiment.
+		Seed int64 `json:"seed"`
+
+		// Membership defines nodes senders choose from: the whole network
+		// (global, default) or partial views of ViewSize nodes, see
+		// model.MembershipNames. Zero ViewSize stands for fan-out+1.
+		Membership string `json:"membership"`
+		ViewSize   int    `json:"view_size"`
+
+		// Crash is a number of random nodes, except initial one, crashed
+		// before propagation. Crashed nodes do not get data, partial views
+		// are repaired and network is filled when alive nodes have data.
+		Crash int `json:"crash"`
+
+		// Antithetic runs experiments in pairs: experiment 2m uses source
+		// seeded with Seed+m and experiment 2m+1 uses its antithetic
+		// source. Random seed is chosen when Seed is zero.
+		Antithetic bool `json:"antithetic"`
+
+		mirror  bool // experiment uses antithetic source
+		isolate bool // experiment isolates random node, see RareEvent
+
+		// StopOnInfinite stops the run at the first experiment which
+		// did not fill the network, the experiment is kept in Result.
+		StopOnInfinite bool `json:"stop_on_infinite"`
+
+		// Record keeps snapshot of the network after each epoch in
+		// Experiment.Frames to replay it later.
+		Record bool `json:"-"`
+	}
+
+	Result struct {
+		Config    Config        `json:"config"`
+		Completed int           `json:"completed"` // number of finished experiments
+		Hops      map[int]int   `json:"hops"`      // number of experiments by hops to fill the network
+		Infinite  int           `json:"infinite"`  // number of experiments which did not fill the network
+		Stalled   int           `json:"stalled"`   // not filled experiments where propagation was over
+		Limited   int           `json:"limited"`   // not filled experiments stopped at epoch limit
+		Missed    map[int]int   `json:"missed"`    // number of experiments by nodes without data at the end
+		Load      Load          `json:"load"`      // per-node message counters over all experiments
+		Reused    int           `json:"reused"`    // total amount of reused messages in filled experiments
+		Messages  model.Stat    `json:"messages"`  // message counters over all experiments
+		Coverage  []float64     `json:"coverage"`  // average coverage after each hop
+		Elapsed   time.Duration `json:"elapsed"`   // duration of the run
+		Canceled  bool          `json:"canceled"`  // run stopped before all experiments were finished
+
+		// StalledCoverage is average final coverage of stalled experiments.
+		StalledCoverage float64 `json:"stalled_coverage"`
+
+		// Latency is a distribution of hops when nodes got data, except
+		// nodes started propagation. LastInformed is a distribution of
+		// hops when the last node of experiment got data. NodeLatency is
+		// average hop when each node got data, -1 if it never did.
+		Latency      stats.Distribution `json:"latency"`
+		LastInformed stats.Distribution `json:"last_informed"`
+		NodeLatency  []float64          `json:"node_latency"`
+
+		// Views is a quality of partial views, zero for global membership.
+		Views Views `json:"views"`
+
+		// InfiniteExperiment is the first experiment which did not fill
+		// the network, set with Config.StopOnInfinite only.
+		InfiniteExperiment *Experiment `json:"-"`
+	}
+
+	// Load describes distributions of per-node message counters of
+	// experiments, e.g. Sent.Max is the most messages sent by single node
+	// in single experiment.
+	Load struct {
+		Sent       stats.Distribution `json:"sent"`
+		Received   stats.Distribution `json:"received"`
+		Duplicates stats.Distribution `json:"duplicates"`
+	}
+
+	// Views describes partial views of alive nodes at the start of
+	// experiments: distribution of in-degree (number of views containing
+	// node) and mean local clustering coefficient.
+	Views struct {
+		InDegree   stats.Distribution `json:"in_degree"`
+		Clustering float64            `json:"clustering"`
+	}
+
+	// Experiment is an outcome of single propagation process.
+	Experiment struct {
+		Network  *model.Network
+		Filled   bool  // all nodes got data
+		Stalled  bool  // propagation was over before the network was filled
+		Epochs   int   // number of the last epoch
+		Sent     int   // amount of sent messages
+		Reused   int   // amount of reused messages
+		Coverage []int // coverage after each hop, starting with initial
+
+		// Messages are message counters summed over epochs, see model.Stat.
+		Messages model.Stat
+
+		// Frames are snapshots of the network, starting with initial
+		// state, set with Config.Record only.
+		Frames []model.Frame
+	}
+
+	Runner struct {
+		cfg     Config
+		counter model.EpochCounter
+
+		mu       sync.Mutex
+		start    time.Time
+		finish   time.Time
+		infinite *Experiment
+	}
+)
+
+var (
+	ErrInvalidSize        = errors.New("network size must be greater than zero")
+	ErrInvalidFanout      = errors.New("fan-out size must be in [1, size-1] range")
+	ErrInvalidExperiments = errors.New("number of experiments must be greater than zero")
+	ErrInvalidNode        = errors.New("leader node is out of network range")
+	ErrInvalidMaxEpochs   = errors.New("epoch limit must not be negative")
+	ErrInvalidProbability = errors.New("forwarding probability must be in [0, 1] range")
+	ErrInvalidForwardHops = errors.New("number of forwarding hops must not be negative")
+	ErrInvalidLowDegree   = errors.New("low degree must not be negative")
+	ErrInvalidTTL         = errors.New("TTL must not be negative")
+	ErrInvalidCrash       = errors.New("number of crashed nodes must be in [0, size-1) range")
+	ErrInvalidViewSize    = errors.New("view size must be in [0, size-1] range")
+)
+
+// Validate checks that experiments can be run with the config.
+func (c Config) Validate() error {
+	switch {
+	case c.Size <= 0:
+		return ErrInvalidSize
+	case c.Fanout <= 0 || c.Fanout > c.Size-1:
+		return ErrInvalidFanout
+	case c.Experiments <= 0:
+		return ErrInvalidExperiments
+	case c.InitialNode < 0 || c.InitialNode >= c.Size:
+		return ErrInvalidNode
+	case c.MaxEpochs < 0:
+		return ErrInvalidMaxEpochs
+	case c.Probability < 0 || c.Probability > 1 || c.LowProbability < 0 || c.LowProbability > 1:
+		return ErrInvalidProbability
+	case c.ForwardHops < 0:
+		return ErrInvalidForwardHops
+	case c.LowDegree < 0:
+		return ErrInvalidLowDegree
+	case c.TTL < 0:
+		return ErrInvalidTTL
+	case c.Crash < 0 || c.Crash > 0 && c.Crash >= c.Size-1:
+		return ErrInvalidCrash
+	case c.ViewSize < 0 || c.ViewSize > c.Size-1:
+		return ErrInvalidViewSize
+	}
+	if err := model.ValidMembership(c.Membership); err != nil {
+		return err
+	}
+	_, err := model.GetAlgorithm(c.Algorithm)
+	return err
+}
+
+// New creates runner for the config.
+func New(cfg Config) (*Runner, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	if cfg.Workers <= 0 {
+		cfg.Workers = runtime.NumCPU() + runtime.NumCPU()/2
+	}
+	if cfg.Algorithm == "" {
+		cfg.Algorithm = model.DefaultAlgorithm
+	}
+	if cfg.Antithetic && cfg.Seed == 0 {
+		cfg.Seed = randomSeed()
+	}
+	return &Runner{
+		cfg: cfg,
+		counter: model.EpochCounter{
+			Mu:      new(sync.Mutex),
+			Counter: make(map[int]int),
+		},
+	}, nil
+}
+
+// Config returns configuration of the runner.
+func (r *Runner) Config() Config {
+	return r.cfg
+}
+
+// Run executes experiments and returns aggregated result. When ctx is done
+// before all experiments are finished, workers complete current experiments
+// and result contains partial aggregates. Runner must not be reused.
+func (r *Runner) Run(ctx context.Context) Result {
+	r.mu.Lock()
+	r.start = time.Now()
+	r.mu.Unlock()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	forEach(ctx, r.cfg.Workers, r.cfg.Experiments, func(k int) {
+		e := Single(r.cfg.experiment(k))
+		r.add(e)
+		if !e.Filled && r.cfg.StopOnInfinite {
+			r.mu.Lock()
+			if r.infinite == nil {
+				r.infinite = &e
+			}
+			r.mu.Unlock()
+			cancel()
+		}
+	})
+
+	r.mu.Lock()
+	r.finish = time.Now()
+	r.mu.Unlock()
+	return r.Progress()
+}
+
+// Progress returns result of experiments completed so far, it is safe
+// to call it concurrently with Run.
+func (r *Runner) Progress() Result {
+	c := r.counter.Snapshot()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	res := Result{
+		Config:             r.cfg,
+		Completed:          c.Total(),
+		Hops:               make(map[int]int, len(c.Counter)),
+		Infinite:           c.InfCounter,
+		Reused:             c.ReCounter,
+		InfiniteExperiment: r.infinite,
+	}
+	for epoch, v := range c.Counter {
+		res.Hops[epoch+1] = v
+	}
+	res.Missed = c.MissCounter
+	res.Messages = c.Messages
+	res.Messages.Coverage = 0
+	res.Latency = stats.Summary(c.LatencyCounter)
+	res.LastInformed = stats.Summary(c.LastCounter)
+	res.NodeLatency = c.NodeLatency()
+	res.Load = Load{
+		Sent:       stats.Summary(c.SentLoad),
+		Received:   stats.Summary(c.ReceivedLoad),
+		Duplicates: stats.Summary(c.DuplicateLoad),
+	}
+	if c.ViewCounter > 0 {
+		res.Views = Views{
+			InDegree:   stats.Summary(c.InDegreeCounter),
+			Clustering: c.ClusteringSum / float64(c.ViewCounter),
+		}
+	}
+	res.Stalled = c.StallCounter
+	res.Limited = res.Infinite - res.Stalled
+	if res.Stalled > 0 {
+		res.StalledCoverage = float64(c.StallCoverage) / float64(res.Stalled)
+	}
+	res.Coverage = c.CoverageCurve(res.Completed)
+	res.Canceled = res.Completed < r.cfg.Experiments
+	switch {
+	case !r.finish.IsZero():
+		res.Elapsed = r.finish.Sub(r.start)
+	case !r.start.IsZero():
+		res.Elapsed = time.Since(r.start)
+	}
+	return res
+}
+
+// MeanHops returns average number of hops to fill the network over
+// filled experiments, zero if there are no such experiments.
+func (r Result) MeanHops() float64 {
+	filled, sum := 0, 0
+	for hop, v := range r.Hops {
+		filled += v
+		sum += hop * v
+	}
+	if filled == 0 {
+		return 0
+	}
+	return float64(sum) / float64(filled)
+}
+
+// MeanReused returns average number of reused messages over filled
+// experiments, zero if there are no such experiments.
+func (r Result) MeanReused() float64 {
+	filled := 0
+	for _, v := range r.Hops {
+		filled += v
+	}
+	if filled == 0 {
+		return 0
+	}
+	return float64(r.Reused) / float64(filled)
+}
+
+// forEach runs job for 0, 1, ..., n-1 in workers goroutines until all
+// jobs are done or ctx is done. Job in progress is always finished, so
+// aggregates of callers stay consistent.
+func forEach(ctx context.Context, workers, n int, job func(k int)) {
+	jobs := make(chan int, n)
+	for k := 0; k < n; k++ {
+		jobs <- k
+	}
+	close(jobs)
+
+	wg := new(sync.WaitGroup)
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for k := range jobs {
+				if ctx.Err() != nil {
+					return
+				}
+				job(k)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// add accounts experiment in aggregated result.
+func (r *Runner) add(e Experiment) {
+	r.counter.AddCoverage(e.Coverage)
+	r.counter.IncMissed(e.Missed())
+	r.counter.AddMessages(e.Messages)
+	r.counter.AddLoad(e.Network.Load())
+	r.counter.AddLatency(e.Network.InfectionEpochs(), len(e.Network.Topology))
+	if v, ok := e.Network.Views(); ok {
+		r.counter.AddViews(v)
+	}
+	if e.Filled {
+		r.counter.Inc(e.Epochs)
+		r.counter.AddRe(e.Reused)
+	} else {
+		r.counter.IncInfiniteCounter()
+	}
+	if e.Stalled {
+		r.counter.AddStalled(e.Coverage[len(e.Coverage)-1])
+	}
+}
+
+// Missed returns number of alive nodes without data at the end of
+// experiment.
+func (e Experiment) Missed() int {
+	return e.Network.Alive() - e.Network.CountCoverage()
+}
+
+// experiment returns config of k-th experiment in the run.
+func (c Config) experiment(k int) Config {
+	switch {
+	case c.Seed == 0:
+	case c.Antithetic:
+		c.Seed += int64(k / 2)
+		c.mirror = k%2 == 1
+	default:
+		c.Seed += int64(k)
+	}
+	return c
+}
+
+// Single runs one experiment of the config, non-zero Config.Seed is used
+// as a seed of the random source. Config must be valid.
+func Single(cfg Config) Experiment {
+	alg, err := model.GetAlgorithm(cfg.Algorithm)
+	if err != nil {
+		panic(err)
+	}
+	quiescent, err := model.GetQuiescence(cfg.Algorithm)
+	if err != nil {
+		panic(err)
+	}
+	maxEpochs := cfg.MaxEpochs
+	if maxEpochs == 0 {
+		maxEpochs = cfg.Size
+	}
+	netmap, err := model.SampleNetwork(cfg.Size)
+	if err != nil {
+		panic(err)
+	}
+	if err = netmap.VisitNode(cfg.InitialNode); err != nil {
+		panic(err)
+	}
+	seed(&netmap, cfg)
+	netmap.SetForwarding(cfg.Probability, cfg.ForwardHops)
+	netmap.SetLowDegreeForwarding(cfg.LowProbability, cfg.LowDegree)
+	netmap.SetTTL(cfg.TTL)
+	sampler, err := model.NewPeerSampler(cfg.Membership, &netmap, cfg.viewSize())
+	if err != nil {
+		panic(err)
+	}
+	netmap.SetPeerSampler(sampler)
+	if cfg.Crash > 0 {
+		netmap.CrashRandomNodes(cfg.Crash, cfg.InitialNode)
+	}
+	if cfg.isolate {
+		netmap.IsolateRandomNode(cfg.InitialNode)
+	}
+
+	i := -1
+	sent, reused := 0, 0
+	var messages model.Stat
+	curve := []int{netmap.CountCoverage()}
+	var frames []model.Frame
+	if cfg.Record {
+		frames = append(frames, netmap.Frame(i, model.Stat{Coverage: curve[0]}))
+	}
+
+	stalled := false
+	for !netmap.IsNetworkFilled() {
+		if quiescent(netmap) {
+			stalled = true
+			break
+		}
+		if i+1 >= maxEpochs {
+			break
+		}
+		i++
+		// Here we calling gossip algorithm
+		stat := alg(&netmap, cfg.Fanout, i)
+		sent += stat.Sent
+		reused += stat.Reused
+		messages.Add(stat)
+		curve = append(curve, stat.Coverage)
+		if cfg.Record {
+			frames = append(frames, netmap.Frame(i, stat))
+		}
+	}
+	return Experiment{
+		Network:  &netmap,
+		Filled:   netmap.IsNetworkFilled(),
+		Stalled:  stalled,
+		Epochs:   i,
+		Sent:     sent,
+		Reused:   reused,
+		Messages: messages,
+		Coverage: curve,
+		Frames:   frames,
+	}
+}
+
+// viewSize returns size of partial views: Config.ViewSize or fan-out+1,
+// at most size-1.
+func (c Config) viewSize() int {
+	size := c.ViewSize
+	if size == 0 {
+		size = c.Fanout + 1
+	}
+	if size > c.Size-1 {
+		size = c.Size - 1
+	}
+	return size
+}
+
+// seed sets random source of the experiment to the network.
+func seed(netmap *model.Network, cfg Config) {
+	switch {
+	case cfg.mirror:
+		netmap.SetRandSource(model.AntitheticSource{Source: rand.NewSource(cfg.Seed)})
+	case cfg.Seed != 0:
+		netmap.SetRandSource(rand.NewSource(cfg.Seed))
+	}
+}
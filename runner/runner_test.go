@@ -0,0 +1,214 @@
+package runner
+
+import (
+	"context"
+	"gossipmodel/model"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfig_Validate(t *testing.T) {
+	cfg := Config{Size: 10, Fanout: 3, Experiments: 1}
+	require.NoError(t, cfg.Validate())
+
+	cfg.Fanout = 10
+	require.Equal(t, ErrInvalidFanout, cfg.Validate())
+
+	cfg = Config{Size: 10, Fanout: 3, Experiments: 1, InitialNode: 10}
+	require.Equal(t, ErrInvalidNode, cfg.Validate())
+
+	_, err := New(Config{Fanout: 3, Experiments: 1})
+	require.Equal(t, ErrInvalidSize, err)
+}
+
+func TestRunner_Run(t *testing.T) {
+	r, err := New(Config{Size: 10, Fanout: 9, Experiments: 20})
+	require.NoError(t, err)
+
+	res := r.Run(context.Background())
+	require.False(t, res.Canceled)
+	require.Equal(t, 20, res.Completed)
+	require.Equal(t, map[int]int{1: 20}, res.Hops)
+	require.Equal(t, []float64{1, 10}, res.Coverage)
+}
+
+func TestRunner_RunCanceled(t *testing.T) {
+	r, err := New(Config{Size: 10, Fanout: 9, Experiments: 20})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	res := r.Run(ctx)
+	require.True(t, res.Canceled)
+	require.Equal(t, 0, res.Completed)
+}
+
+func TestSingle_Record(t *testing.T) {
+	e := Single(Config{Size: 10, Fanout: 9, Experiments: 1, Record: true})
+	require.True(t, e.Filled)
+	require.Len(t, e.Frames, 2)
+
+	require.Equal(t, -1, e.Frames[0].Epoch)
+	require.Equal(t, 1, e.Frames[0].Topology[0])
+	require.Empty(t, e.Frames[0].Sent)
+
+	require.Equal(t, 0, e.Frames[1].Epoch)
+	require.Equal(t, -1, e.Frames[1].Topology[0])
+	require.Len(t, e.Frames[1].Sent[0], 9)
+	require.Equal(t, 10, e.Frames[1].Stat.Coverage)
+}
+
+func TestConfig_experimentAntithetic(t *testing.T) {
+	cfg := Config{Size: 50, Fanout: 3, Experiments: 4, Seed: 10, Antithetic: true}
+	require.Equal(t, int64(10), cfg.experiment(0).Seed)
+	require.False(t, cfg.experiment(0).mirror)
+	require.Equal(t, int64(10), cfg.experiment(1).Seed)
+	require.True(t, cfg.experiment(1).mirror)
+	require.Equal(t, int64(11), cfg.experiment(2).Seed)
+
+	r, err := New(Config{Size: 50, Fanout: 3, Experiments: 4, Antithetic: true})
+	require.NoError(t, err)
+	require.NotZero(t, r.Config().Seed)
+}
+
+func TestRunner_RunTermination(t *testing.T) {
+	// naive-once with fan-out 1 is a chain, which stalls at the first
+	// node chosen twice
+	r, err := New(Config{Size: 50, Fanout: 1, Experiments: 20, Seed: 1})
+	require.NoError(t, err)
+	res := r.Run(context.Background())
+	require.Equal(t, 20, res.Infinite)
+	require.Equal(t, 20, res.Stalled)
+	require.Equal(t, 0, res.Limited)
+	require.True(t, res.StalledCoverage > 1 && res.StalledCoverage < 50)
+
+	r, err = New(Config{Size: 50, Fanout: 1, Experiments: 20, Seed: 1, Algorithm: "naive-forever", MaxEpochs: 2})
+	require.NoError(t, err)
+	res = r.Run(context.Background())
+	require.Equal(t, 0, res.Stalled)
+	require.Equal(t, 20, res.Limited)
+	require.Len(t, res.Coverage, 3)
+
+	_, err = New(Config{Size: 50, Fanout: 1, Experiments: 20, MaxEpochs: -1})
+	require.Equal(t, ErrInvalidMaxEpochs, err)
+}
+
+func TestRunner_RunTTL(t *testing.T) {
+	// data of naive-once travels at most TTL hops, then propagation stalls
+	r, err := New(Config{Size: 200, Fanout: 3, Experiments: 20, Seed: 1, TTL: 2})
+	require.NoError(t, err)
+	res := r.Run(context.Background())
+	require.Equal(t, 20, res.Infinite)
+	require.Equal(t, 20, res.Stalled)
+	require.True(t, res.StalledCoverage <= 1+3+9)
+	require.True(t, res.MeanFinalCoverage() < 0.1)
+
+	_, err = New(Config{Size: 50, Fanout: 1, Experiments: 20, TTL: -1})
+	require.Equal(t, ErrInvalidTTL, err)
+}
+
+func TestRunner_RunMembership(t *testing.T) {
+	cfg := Config{Size: 200, Fanout: 4, Experiments: 20, Seed: 1, Membership: "hyparview", Crash: 20}
+	r, err := New(cfg)
+	require.NoError(t, err)
+	res := r.Run(context.Background())
+	require.Equal(t, 20, res.Completed)
+	require.True(t, res.MeanFinalCoverage() > 0.9)
+	for missed := range res.Missed {
+		require.True(t, missed <= 180)
+	}
+
+	// crashed nodes are not counted as missed
+	cfg = Config{Size: 50, Fanout: 49, Experiments: 5, Crash: 10}
+	r, err = New(cfg)
+	require.NoError(t, err)
+	res = r.Run(context.Background())
+	require.Equal(t, 1.0, res.Atomic())
+	require.Equal(t, 5, res.Hops[1])
+
+	_, err = New(Config{Size: 50, Fanout: 1, Experiments: 1, Crash: 49})
+	require.Equal(t, ErrInvalidCrash, err)
+	_, err = New(Config{Size: 50, Fanout: 1, Experiments: 1, Membership: "scamp"})
+	require.Equal(t, model.ErrUnknownMembership, err)
+	_, err = RareEvent(context.Background(), Config{Size: 50, Fanout: 2, Experiments: 1, Crash: 1})
+	require.Equal(t, ErrRareMembership, err)
+}
+
+func TestRunner_RunViews(t *testing.T) {
+	r, err := New(Config{Size: 200, Fanout: 4, Experiments: 5, Seed: 1, Membership: "cyclon"})
+	require.NoError(t, err)
+	res := r.Run(context.Background())
+	require.Equal(t, 5, res.Completed)
+	require.Equal(t, 5*200, res.Views.InDegree.Count)
+	require.InDelta(t, 5.0, res.Views.InDegree.Mean, 0.1) // view size is fan-out+1
+	require.True(t, res.Views.Clustering > 0)
+
+	r, err = New(Config{Size: 50, Fanout: 4, Experiments: 5, Membership: "static", ViewSize: 10})
+	require.NoError(t, err)
+	res = r.Run(context.Background())
+	require.Equal(t, 10.0, res.Views.InDegree.Mean)
+
+	r, err = New(Config{Size: 50, Fanout: 4, Experiments: 5})
+	require.NoError(t, err)
+	res = r.Run(context.Background())
+	require.Equal(t, 0, res.Views.InDegree.Count)
+
+	_, err = New(Config{Size: 50, Fanout: 4, Experiments: 1, Membership: "cyclon", ViewSize: 50})
+	require.Equal(t, ErrInvalidViewSize, err)
+}
+
+func TestRunner_RunLatency(t *testing.T) {
+	r, err := New(Config{Size: 10, Fanout: 9, Experiments: 20, InitialNode: 3})
+	require.NoError(t, err)
+	res := r.Run(context.Background())
+	require.Equal(t, 9*20, res.Latency.Count)
+	require.Equal(t, 1, res.Latency.Max)
+	require.Equal(t, 20, res.LastInformed.Count)
+	require.Equal(t, 1.0, res.LastInformed.Mean)
+	require.Len(t, res.NodeLatency, 10)
+	require.Equal(t, 0.0, res.NodeLatency[3])
+	require.Equal(t, 1.0, res.NodeLatency[0])
+
+	r, err = New(Config{Size: 50, Fanout: 1, Experiments: 20, Seed: 1})
+	require.NoError(t, err)
+	res = r.Run(context.Background())
+	require.Contains(t, res.NodeLatency, -1.0)
+	require.True(t, res.LastInformed.Mean >= res.Latency.Mean)
+}
+
+func TestResult_MeanReused(t *testing.T) {
+	// reused messages are accounted in filled experiments only
+	res := Result{Completed: 4, Hops: map[int]int{2: 1, 3: 1}, Infinite: 2, Reused: 30}
+	require.Equal(t, 15.0, res.MeanReused())
+	require.Equal(t, 0.0, Result{}.MeanReused())
+
+	r, err := New(Config{Size: 30, Fanout: 4, Experiments: 50, Seed: 1})
+	require.NoError(t, err)
+	res = r.Run(context.Background())
+	m := res.Messages
+	require.Equal(t, m.Sent, m.Delivered+m.Stale+m.Concurrent)
+	require.Equal(t, m.Reused, m.Stale+m.Concurrent)
+	require.True(t, res.Reused <= m.Reused)
+}
+
+func TestForEach(t *testing.T) {
+	done := make([]bool, 100)
+	forEach(context.Background(), 4, len(done), func(k int) {
+		done[k] = true
+	})
+	for _, ok := range done {
+		require.True(t, ok)
+	}
+
+	// job in progress is finished, the rest is not run
+	ctx, cancel := context.WithCancel(context.Background())
+	runs := 0
+	forEach(ctx, 1, 100, func(k int) {
+		runs++
+		if k == 9 {
+			cancel()
+		}
+	})
+	require.Equal(t, 10, runs)
+}
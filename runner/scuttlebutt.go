@@ -0,0 +1,200 @@
+package runner
+
+/*
+	Reconciliation experiment runs epochs of replicated state protocols,
+	see model.Reconciliation, over new network of the config: nodes update
+	their keys in the first half of epochs, so the second half shows how
+	protocols converge. Every protocol runs the same experiments, nodes
+	gossip with fan-out peers of the membership per epoch.
+*/
+
+import (
+	"context"
+	"errors"
+	"gossipmodel/model"
+	"time"
+)
+
+type (
+	// ReconcileConfig describes reconciliation experiment.
+	ReconcileConfig struct {
+		Epochs     int     `json:"epochs"`      // epochs of experiment
+		Keys       int     `json:"keys"`        // keys of every node, zero stands for model.DefaultKeys
+		UpdateRate float64 `json:"update_rate"` // probability node updates a key in epoch
+		MTU        int     `json:"mtu"`         // deltas per exchange, zero stands for model.DefaultMTU
+	}
+
+	// ReconcileProtocol is an average of experiments of single protocol.
+	ReconcileProtocol struct {
+		Name string `json:"name"`
+
+		// Staleness is a mean share of entries behind owners after each
+		// epoch, Converged is a share of experiments which ended with all
+		// entries up to date.
+		Staleness []float64 `json:"staleness"`
+		Converged float64   `json:"converged"`
+
+		// Bytes and Deltas are mean numbers per exchange, Redundant is a
+		// share of deltas receivers already had.
+		Bytes     float64 `json:"bytes"`
+		Deltas    float64 `json:"deltas"`
+		Redundant float64 `json:"redundant"`
+	}
+
+	ReconcileResult struct {
+		Config    Config              `json:"config"`
+		Reconcile ReconcileConfig     `json:"reconcile"`
+		Protocols []ReconcileProtocol `json:"protocols"`
+		Completed int                 `json:"completed"` // number of finished experiments of every protocol
+		Elapsed   time.Duration       `json:"elapsed"`
+		Canceled  bool                `json:"canceled"`
+	}
+
+	// reconcileSum is a sum of experiments of single protocol.
+	reconcileSum struct {
+		staleness []float64
+		converged int
+		completed int
+		stat      model.ReconcileStat
+	}
+)
+
+var (
+	ErrInvalidEpochs     = errors.New("number of epochs must be greater than zero")
+	ErrInvalidKeys       = errors.New("number of keys must not be negative")
+	ErrInvalidUpdateRate = errors.New("update rate must be in [0, 1] range")
+	ErrInvalidMTU        = errors.New("MTU must not be negative")
+)
+
+// Reconcile runs cfg.Experiments reconciliation experiments of every
+// protocol. When ctx is done, results are averaged over experiments
+// completed by all protocols, so protocols are compared on the same
+// experiments.
+func Reconcile(ctx context.Context, cfg Config, rcfg ReconcileConfig) (ReconcileResult, error) {
+	switch {
+	case rcfg.Epochs <= 0:
+		return ReconcileResult{}, ErrInvalidEpochs
+	case rcfg.Keys < 0:
+		return ReconcileResult{}, ErrInvalidKeys
+	case rcfg.UpdateRate < 0 || rcfg.UpdateRate > 1:
+		return ReconcileResult{}, ErrInvalidUpdateRate
+	case rcfg.MTU < 0:
+		return ReconcileResult{}, ErrInvalidMTU
+	}
+	if rcfg.Keys == 0 {
+		rcfg.Keys = model.DefaultKeys
+	}
+	if rcfg.MTU == 0 {
+		rcfg.MTU = model.DefaultMTU
+	}
+	// nodes do not crash
+	cfg.Crash = 0
+	r, err := New(cfg)
+	if err != nil {
+		return ReconcileResult{}, err
+	}
+	cfg = r.cfg
+
+	names := model.ProtocolNames()
+	epochs := make([][][]model.ReconcileStat, len(names))
+	for i := range epochs {
+		epochs[i] = make([][]model.ReconcileStat, cfg.Experiments)
+	}
+	start := time.Now()
+	// job runs k-th experiment of i-th protocol, each job writes only its
+	// own stats, so no locks needed
+	forEach(ctx, cfg.Workers, cfg.Experiments*len(names), func(job int) {
+		i, k := job%len(names), job/len(names)
+		epochs[i][k] = reconcileExperiment(cfg.experiment(k), rcfg, names[i])
+	})
+
+	res := ReconcileResult{
+		Config:    cfg,
+		Reconcile: rcfg,
+		Elapsed:   time.Since(start),
+	}
+	sums := make([]reconcileSum, len(names))
+	for i := range sums {
+		sums[i].staleness = make([]float64, rcfg.Epochs)
+	}
+	for k := 0; k < cfg.Experiments; k++ {
+		if !completed(epochs, k) {
+			continue
+		}
+		for i := range sums {
+			sums[i].add(epochs[i][k])
+		}
+		res.Completed++
+	}
+	for i, sum := range sums {
+		res.Protocols = append(res.Protocols, sum.average(names[i]))
+	}
+	res.Canceled = res.Completed < cfg.Experiments
+	return res, nil
+}
+
+// completed reports that k-th experiment is completed by all protocols.
+func completed(epochs [][][]model.ReconcileStat, k int) bool {
+	for _, experiments := range epochs {
+		if experiments[k] == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// reconcileExperiment runs epochs of the protocol over new network of the
+// config and returns stats of every epoch.
+func reconcileExperiment(cfg Config, rcfg ReconcileConfig, protocol string) []model.ReconcileStat {
+	netmap, err := model.SampleNetwork(cfg.Size)
+	if err != nil {
+		panic(err)
+	}
+	seed(&netmap, cfg)
+	sampler, err := model.NewPeerSampler(cfg.Membership, &netmap, cfg.viewSize())
+	if err != nil {
+		panic(err)
+	}
+	netmap.SetPeerSampler(sampler)
+	rec, err := model.NewReconciliation(&netmap, protocol, rcfg.Keys, rcfg.MTU, rcfg.UpdateRate)
+	if err != nil {
+		panic(err)
+	}
+	epochs := make([]model.ReconcileStat, rcfg.Epochs)
+	for epoch := range epochs {
+		epochs[epoch] = rec.Epoch(cfg.Fanout, epoch < rcfg.Epochs/2)
+	}
+	return epochs
+}
+
+func (sum *reconcileSum) add(epochs []model.ReconcileStat) {
+	for epoch, s := range epochs {
+		sum.staleness[epoch] += s.Staleness
+		sum.stat.Stat.Add(s.Stat)
+		sum.stat.Exchanges += s.Exchanges
+		sum.stat.Bytes += s.Bytes
+	}
+	if epochs[len(epochs)-1].Staleness == 0 {
+		sum.converged++
+	}
+	sum.completed++
+}
+
+func (sum reconcileSum) average(name string) ReconcileProtocol {
+	p := ReconcileProtocol{Name: name, Staleness: sum.staleness}
+	if sum.completed == 0 {
+		return p
+	}
+	for epoch := range p.Staleness {
+		p.Staleness[epoch] /= float64(sum.completed)
+	}
+	p.Converged = float64(sum.converged) / float64(sum.completed)
+	if s := sum.stat; s.Exchanges > 0 {
+		p.Bytes = float64(s.Bytes) / float64(s.Exchanges)
+		p.Deltas = float64(s.Sent) / float64(s.Exchanges)
+	}
+	if s := sum.stat; s.Sent > 0 {
+		p.Redundant = float64(s.Reused) / float64(s.Sent)
+	}
+	return p
+}
@@ -0,0 +1,81 @@
+package runner
+
+import (
+	"context"
+	"gossipmodel/model"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReconcile(t *testing.T) {
+	cfg := Config{Size: 50, Fanout: 2, Experiments: 4, Seed: 1}
+	_, err := Reconcile(context.Background(), cfg, ReconcileConfig{Epochs: 10, MTU: -1})
+	require.Equal(t, ErrInvalidMTU, err)
+
+	res, err := Reconcile(context.Background(), cfg, ReconcileConfig{Epochs: 30, UpdateRate: 0.5, MTU: 1000})
+	require.NoError(t, err)
+	require.Equal(t, 4, res.Completed)
+	require.Equal(t, model.DefaultKeys, res.Reconcile.Keys)
+	require.Len(t, res.Protocols, 3)
+	byName := map[string]ReconcileProtocol{}
+	for _, p := range res.Protocols {
+		require.Len(t, p.Staleness, 30)
+		require.True(t, p.Staleness[0] > 0)
+		byName[p.Name] = p
+	}
+
+	// without MTU limit scuttlebutt spreads updates as anti-entropy with
+	// fewer bytes
+	anti, scuttle := byName[model.AntiEntropyProtocol], byName[model.ScuttlebuttProtocol]
+	require.Equal(t, anti.Staleness, scuttle.Staleness)
+	require.Equal(t, 1.0, scuttle.Converged)
+	require.True(t, scuttle.Bytes < anti.Bytes/2)
+	require.True(t, scuttle.Redundant < anti.Redundant)
+	require.True(t, byName[model.PushProtocol].Staleness[29] > 0)
+}
+
+func TestReconcile_MTU(t *testing.T) {
+	cfg := Config{Size: 50, Fanout: 2, Experiments: 4, Seed: 1}
+	res, err := Reconcile(context.Background(), cfg, ReconcileConfig{Epochs: 30, UpdateRate: 1, MTU: 4})
+	require.NoError(t, err)
+	byName := map[string]ReconcileProtocol{}
+	for _, p := range res.Protocols {
+		byName[p.Name] = p
+	}
+
+	// scuttlebutt exchanges both digests and at most MTU deltas in each
+	// direction, push sends at most MTU rumors, anti-entropy is not limited
+	scuttle := byName[model.ScuttlebuttProtocol]
+	bound := float64(2*50*model.DigestBytes + 2*4*model.DeltaBytes)
+	require.True(t, scuttle.Bytes <= bound)
+	require.True(t, scuttle.Deltas <= 2*4)
+	push := byName[model.PushProtocol]
+	require.True(t, push.Bytes <= 4*model.DeltaBytes)
+	require.True(t, push.Deltas <= 4)
+	require.True(t, byName[model.AntiEntropyProtocol].Bytes > bound)
+}
+
+func TestReconcile_Canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	res, err := Reconcile(ctx, Config{Size: 30, Fanout: 2, Experiments: 3, Seed: 1}, ReconcileConfig{Epochs: 10})
+	require.NoError(t, err)
+	require.True(t, res.Canceled)
+	require.Equal(t, 0, res.Completed)
+	for _, p := range res.Protocols {
+		require.Equal(t, make([]float64, 10), p.Staleness)
+		require.Equal(t, 0.0, p.Bytes)
+	}
+}
+
+func TestCompleted(t *testing.T) {
+	// the second experiment is done by the first protocol only, so it is
+	// not averaged
+	epochs := [][][]model.ReconcileStat{
+		{{{}}, {{}}},
+		{{{}}, nil},
+	}
+	require.True(t, completed(epochs, 0))
+	require.False(t, completed(epochs, 1))
+}
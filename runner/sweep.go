@@ -0,0 +1,105 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"strconv"
+)
+
+type (
+	// Axis is a range of config parameter values in sweep.
+	Axis struct {
+		Param  string `json:"param"`
+		Values []int  `json:"values"`
+	}
+
+	// SweepResult contains results of all parameter combinations,
+	// the last axis changes the fastest.
+	SweepResult struct {
+		Axes     []Axis   `json:"axes"`
+		Results  []Result `json:"results"`
+		Canceled bool     `json:"canceled"`
+	}
+)
+
+var (
+	ErrInvalidAxis = errors.New("sweep axis must have integer parameter and at least one value")
+)
+
+// axisParams are integer config parameters, which can be swept.
+var axisParams = map[string]bool{
+	"size": true, "fanout": true, "experiments": true, "node": true,
+	"workers": true, "max-epochs": true, "forward-hops": true,
+	"low-degree": true, "ttl": true, "view-size": true, "crash": true,
+}
+
+// NewAxis creates axis of param values from min to max with step.
+func NewAxis(param string, min, max, step int) (Axis, error) {
+	if _, err := DefaultConfig().Get(param); err != nil {
+		return Axis{}, err
+	}
+	if step <= 0 || min > max || !axisParams[param] {
+		return Axis{}, ErrInvalidAxis
+	}
+	a := Axis{Param: param}
+	for v := min; v <= max; v += step {
+		a.Values = append(a.Values, v)
+	}
+	return a, nil
+}
+
+// Sweep runs experiments of base config for every combination of axes
+// values. All combinations use common random numbers: the same seed,
+// which is chosen randomly when base.Seed is zero. Invalid combinations
+// (e.g. fan-out larger than network) are skipped with empty result.
+// When ctx is done, the rest of combinations is not run.
+func Sweep(ctx context.Context, base Config, axes ...Axis) (SweepResult, error) {
+	if len(axes) == 0 {
+		return SweepResult{}, ErrInvalidAxis
+	}
+	if base.Seed == 0 {
+		base.Seed = randomSeed()
+	}
+	for _, a := range axes {
+		if len(a.Values) == 0 || !axisParams[a.Param] {
+			return SweepResult{}, ErrInvalidAxis
+		}
+	}
+
+	res := SweepResult{Axes: axes}
+	idx := make([]int, len(axes))
+	for {
+		cfg := base
+		for i, a := range axes {
+			if err := cfg.Set(a.Param, strconv.Itoa(a.Values[idx[i]])); err != nil {
+				return res, err
+			}
+		}
+
+		if ctx.Err() != nil {
+			res.Canceled = true
+			return res, nil
+		}
+		var result Result
+		if r, err := New(cfg); err == nil {
+			result = r.Run(ctx)
+			res.Canceled = res.Canceled || result.Canceled
+		} else {
+			result.Config = cfg
+		}
+		res.Results = append(res.Results, result)
+
+		// next combination, the last axis changes the fastest
+		i := len(axes) - 1
+		for ; i >= 0; i-- {
+			idx[i]++
+			if idx[i] < len(axes[i].Values) {
+				break
+			}
+			idx[i] = 0
+		}
+		if i < 0 {
+			return res, nil
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package runner
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfig_Set(t *testing.T) {
+	cfg := DefaultConfig()
+	require.NoError(t, cfg.Set("fanout", "5"))
+	require.NoError(t, cfg.Set("algorithm", "vector-once"))
+	require.Equal(t, 5, cfg.Fanout)
+	require.Equal(t, "vector-once", cfg.Algorithm)
+
+	require.Error(t, cfg.Set("algorithm", "unknown"))
+	require.Error(t, cfg.Set("size", "ten"))
+	require.Equal(t, ErrUnknownParam, cfg.Set("speed", "1"))
+
+	require.NoError(t, cfg.Reset("fanout"))
+	v, err := cfg.Get("fanout")
+	require.NoError(t, err)
+	require.Equal(t, "10", v)
+}
+
+func TestSweep(t *testing.T) {
+	size, err := NewAxis("size", 5, 10, 5)
+	require.NoError(t, err)
+	require.Equal(t, []int{5, 10}, size.Values)
+
+	fanout, err := NewAxis("fanout", 4, 9, 5)
+	require.NoError(t, err)
+
+	for _, param := range []string{"algorithm", "seed", "antithetic", "probability", "low-probability", "membership"} {
+		_, err = NewAxis(param, 1, 2, 1)
+		require.Equal(t, ErrInvalidAxis, err, param)
+	}
+	_, err = NewAxis("speed", 1, 2, 1)
+	require.Equal(t, ErrUnknownParam, err)
+	_, err = Sweep(context.Background(), Config{}, Axis{Param: "antithetic", Values: []int{0, 1}})
+	require.Equal(t, ErrInvalidAxis, err)
+
+	base := Config{Experiments: 3, Algorithm: "naive-once"}
+	res, err := Sweep(context.Background(), base, size, fanout)
+	require.NoError(t, err)
+	require.Len(t, res.Results, 4)
+
+	// fan-out 9 is too large for network of 5 nodes
+	require.Equal(t, 0, res.Results[1].Completed)
+	require.Equal(t, 9, res.Results[1].Config.Fanout)
+	require.Equal(t, 3, res.Results[3].Completed)
+	require.Equal(t, 1.0, res.Results[3].MeanHops())
+}
@@ -0,0 +1,191 @@
+package runner
+
+/*
+	SWIM experiment runs protocol periods of SWIM failure detector, see
+	model.Swim, over new network of the config. Nodes crash before the
+	first period, detection times are measured from the crash, false
+	suspicions and false positives are counted over all periods. Messages
+	may be lost by the loss model.
+*/
+
+import (
+	"context"
+	"errors"
+	"gossipmodel/model"
+	"gossipmodel/stats"
+	"sync"
+	"time"
+)
+
+type (
+	// SwimConfig describes SWIM experiment.
+	SwimConfig struct {
+		Periods int `json:"periods"` // protocol periods of experiment
+		Crash   int `json:"crash"`   // number of nodes crashed before the first period
+
+		// IndirectProbes is a number of PING-REQ without ack, zero stands
+		// for direct probes only, see model.DefaultIndirectProbes.
+		// SuspicionTimeout is a number of periods before suspect is
+		// confirmed dead, zero stands for model.DefaultSuspicionTimeout of
+		// the size.
+		IndirectProbes   int `json:"indirect_probes"`
+		SuspicionTimeout int `json:"suspicion_timeout"`
+
+		// Loss is a name of message loss model, see model.LossNames.
+		Loss     string  `json:"loss"`
+		LossRate float64 `json:"loss_rate"`
+	}
+
+	// SwimMessages is an average number of messages per alive node per
+	// period.
+	SwimMessages struct {
+		Ping    float64 `json:"ping"`
+		PingReq float64 `json:"ping_req"`
+		Ack     float64 `json:"ack"`
+		Lost    float64 `json:"lost"`
+		Updates float64 `json:"updates"` // piggybacked membership updates
+	}
+
+	SwimResult struct {
+		Config    Config        `json:"config"`
+		Swim      SwimConfig    `json:"swim"`
+		Completed int           `json:"completed"` // number of finished experiments
+		Elapsed   time.Duration `json:"elapsed"`
+		Canceled  bool          `json:"canceled"`
+
+		Messages SwimMessages `json:"messages"`
+
+		// Suspicion is a distribution of periods from crash until the
+		// first suspicion, Detection is a distribution of periods until
+		// all alive nodes confirmed crashed node dead. Undetected is a
+		// number of crashed nodes some alive nodes did not confirm.
+		Suspicion  stats.Distribution `json:"suspicion"`
+		Detection  stats.Distribution `json:"detection"`
+		Undetected int                `json:"undetected"`
+
+		// FalseSuspicions and FalsePositives are numbers of incarnations
+		// of alive nodes suspected and confirmed dead per alive node per
+		// period.
+		FalseSuspicions float64 `json:"false_suspicions"`
+		FalsePositives  float64 `json:"false_positives"`
+	}
+
+	// swimExperiment is an outcome of single SWIM experiment.
+	swimExperiment struct {
+		stat      model.SwimStat
+		alive     int
+		suspicion []int
+		detection []int
+	}
+)
+
+var (
+	ErrInvalidPeriods   = errors.New("number of periods must be greater than zero")
+	ErrInvalidProbes    = errors.New("number of indirect probes must not be negative")
+	ErrInvalidSuspicion = errors.New("suspicion timeout must not be negative")
+)
+
+// Swim runs cfg.Experiments SWIM experiments of cfg.Size nodes. When ctx is
+// done, results are aggregated over completed experiments.
+func Swim(ctx context.Context, cfg Config, scfg SwimConfig) (SwimResult, error) {
+	switch {
+	case scfg.Periods <= 0:
+		return SwimResult{}, ErrInvalidPeriods
+	case scfg.Crash < 0 || scfg.Crash >= cfg.Size-1:
+		return SwimResult{}, ErrInvalidCrash
+	case scfg.IndirectProbes < 0:
+		return SwimResult{}, ErrInvalidProbes
+	case scfg.SuspicionTimeout < 0:
+		return SwimResult{}, ErrInvalidSuspicion
+	}
+	if err := model.ValidLoss(scfg.Loss, scfg.LossRate); err != nil {
+		return SwimResult{}, err
+	}
+	if scfg.SuspicionTimeout == 0 {
+		scfg.SuspicionTimeout = model.DefaultSuspicionTimeout(cfg.Size)
+	}
+	if scfg.Loss == "" {
+		scfg.Loss = model.NoLoss
+	}
+	// membership and crashes of propagation do not apply
+	cfg.Membership, cfg.Crash = "", 0
+	r, err := New(cfg)
+	if err != nil {
+		return SwimResult{}, err
+	}
+	cfg = r.cfg
+	res := SwimResult{Config: cfg, Swim: scfg}
+
+	start := time.Now()
+	var total model.SwimStat
+	nodePeriods := 0
+	suspicion := make(map[int]int)
+	detection := make(map[int]int)
+	mu := new(sync.Mutex)
+	forEach(ctx, cfg.Workers, cfg.Experiments, func(k int) {
+		e := runSwim(cfg.experiment(k), scfg)
+		mu.Lock()
+		total.Add(e.stat)
+		nodePeriods += e.alive * scfg.Periods
+		for _, periods := range e.suspicion {
+			suspicion[periods]++
+		}
+		for _, periods := range e.detection {
+			detection[periods]++
+		}
+		res.Undetected += scfg.Crash - len(e.detection)
+		res.Completed++
+		mu.Unlock()
+	})
+	res.Canceled = res.Completed < cfg.Experiments
+	res.Elapsed = time.Since(start)
+	res.Suspicion = stats.Summary(suspicion)
+	res.Detection = stats.Summary(detection)
+	if nodePeriods > 0 {
+		k := 1 / float64(nodePeriods)
+		res.Messages = SwimMessages{
+			Ping:    float64(total.Ping) * k,
+			PingReq: float64(total.PingReq) * k,
+			Ack:     float64(total.Ack) * k,
+			Lost:    float64(total.Lost) * k,
+			Updates: float64(total.Updates) * k,
+		}
+		res.FalseSuspicions = float64(total.FalseSuspicions) * k
+		res.FalsePositives = float64(total.FalsePositives) * k
+	}
+	return res, nil
+}
+
+// runSwim runs periods of SWIM over new network of the config and crashes
+// nodes before the first period.
+func runSwim(cfg Config, scfg SwimConfig) swimExperiment {
+	netmap, err := model.SampleNetwork(cfg.Size)
+	if err != nil {
+		panic(err)
+	}
+	seed(&netmap, cfg)
+	loss, err := model.NewLoss(scfg.Loss, &netmap, scfg.LossRate)
+	if err != nil {
+		panic(err)
+	}
+	sw := model.NewSwim(&netmap)
+	sw.SetIndirectProbes(scfg.IndirectProbes)
+	sw.SetSuspicionTimeout(scfg.SuspicionTimeout)
+	sw.SetLoss(loss)
+
+	var e swimExperiment
+	crashed := sw.CrashRandom(scfg.Crash)
+	e.alive = sw.Alive()
+	for p := 0; p < scfg.Periods; p++ {
+		e.stat.Add(sw.Period())
+	}
+	for _, node := range crashed {
+		if periods, ok := sw.Suspected(node); ok {
+			e.suspicion = append(e.suspicion, periods)
+		}
+		if periods, ok := sw.Confirmed(node); ok {
+			e.detection = append(e.detection, periods)
+		}
+	}
+	return e
+}
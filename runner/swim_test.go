@@ -0,0 +1,44 @@
+package runner
+
+import (
+	"context"
+	"gossipmodel/model"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSwim(t *testing.T) {
+	cfg := Config{Size: 100, Fanout: 4, Experiments: 10, Seed: 1}
+	_, err := Swim(context.Background(), cfg, SwimConfig{Periods: 10, IndirectProbes: -1})
+	require.Equal(t, ErrInvalidProbes, err)
+
+	// without loss alive nodes always ack, so they are never suspected and
+	// all crashed nodes are confirmed dead
+	res, err := Swim(context.Background(), cfg, SwimConfig{Periods: 40, Crash: 5, IndirectProbes: model.DefaultIndirectProbes})
+	require.NoError(t, err)
+	require.Equal(t, 10, res.Completed)
+	require.Equal(t, 8, res.Swim.SuspicionTimeout)
+	require.Equal(t, model.NoLoss, res.Swim.Loss)
+	require.Equal(t, 0.0, res.FalseSuspicions)
+	require.Equal(t, 0.0, res.FalsePositives)
+	require.Equal(t, 0, res.Undetected)
+	require.Equal(t, 50, res.Suspicion.Count)
+	require.Equal(t, 50, res.Detection.Count)
+	require.True(t, res.Detection.Mean > res.Suspicion.Mean+8)
+	require.True(t, res.Messages.PingReq > 0)
+
+	// zero indirect probes stands for direct probes only
+	res, err = Swim(context.Background(), cfg, SwimConfig{Periods: 40, Crash: 5})
+	require.NoError(t, err)
+	require.Equal(t, 0, res.Swim.IndirectProbes)
+	require.Equal(t, 0.0, res.Messages.PingReq)
+	require.Equal(t, 50, res.Detection.Count)
+
+	// lost messages make false suspicions
+	res, err = Swim(context.Background(), cfg, SwimConfig{Periods: 40, IndirectProbes: model.DefaultIndirectProbes, Loss: model.UniformLoss, LossRate: 0.2})
+	require.NoError(t, err)
+	require.True(t, res.Messages.Lost > 0)
+	require.True(t, res.FalseSuspicions > 0)
+	require.Equal(t, 0, res.Suspicion.Count)
+}
@@ -0,0 +1,356 @@
+package server
+
+/*
+	HTTP/JSON API and web UI of the model. Experiments are submitted as jobs, which
+	run in background and can be polled, fetched and canceled:
+
+	`- POST   /experiments                   : submit runner.Config, returns job status
+	`- GET    /experiments                   : list of all jobs
+	`- GET    /experiments/{id}              : job status with progress
+	`- GET    /experiments/{id}/result       : aggregated result of the job
+	`- GET    /experiments/{id}/coverage     : average coverage after each hop
+	`- GET    /experiments/{id}/hops.svg     : chart of hop distribution
+	`- GET    /experiments/{id}/coverage.svg : chart of average coverage
+	`- DELETE /experiments/{id}              : cancel the job
+	`- GET    /algorithms                    : names of available algorithms
+	`- GET    /                              : web UI
+
+	Configs and request bodies above fixed limits are rejected, so single
+	request can not exhaust the server. At most MaxRunning jobs run at the
+	same time and at most MaxJobs jobs are kept: the oldest finished jobs
+	are removed when new one is submitted.
+*/
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"gossipmodel/model"
+	"gossipmodel/report"
+	"gossipmodel/runner"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	StateRunning  = "running"
+	StateDone     = "done"
+	StateCanceled = "canceled"
+)
+
+// Limits of submitted configs and kept jobs.
+const (
+	MaxSize        = 10000
+	MaxExperiments = 100000
+	MaxWorkers     = 64
+	MaxEpochs      = 100000
+	MaxRunning     = 4
+	MaxJobs        = 100
+	MaxBody        = 16 << 10 // bytes of submitted config
+)
+
+type (
+	// Server keeps submitted jobs and serves HTTP API.
+	Server struct {
+		ctx    context.Context
+		mu     sync.Mutex
+		lastID int
+		jobs   map[int]*job
+	}
+
+	job struct {
+		id     int
+		runner *runner.Runner
+		cancel context.CancelFunc
+		done   chan struct{}
+		result runner.Result // set when done is closed
+	}
+
+	// Status describes job state and its progress.
+	Status struct {
+		ID        int           `json:"id"`
+		State     string        `json:"state"`
+		Config    runner.Config `json:"config"`
+		Completed int           `json:"completed"`
+		Total     int           `json:"total"`
+	}
+
+	errorResponse struct {
+		Error string `json:"error"`
+	}
+)
+
+var (
+	ErrSizeLimit        = errors.New("network size exceeds server limit")
+	ErrExperimentsLimit = errors.New("number of experiments exceeds server limit")
+	ErrWorkersLimit     = errors.New("number of workers exceeds server limit")
+	ErrEpochsLimit      = errors.New("epoch limit exceeds server limit")
+	ErrTooManyJobs      = errors.New("too many running jobs")
+)
+
+// New creates server, jobs are canceled when ctx is done.
+func New(ctx context.Context) *Server {
+	return &Server{
+		ctx:  ctx,
+		jobs: make(map[int]*job),
+	}
+}
+
+// ServeHTTP implements http.Handler interface.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	switch {
+	case r.URL.Path == "/" && r.Method == http.MethodGet:
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		io.WriteString(w, indexHTML)
+		return
+	case r.URL.Path == "/algorithms" && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, model.AlgorithmNames())
+		return
+	}
+	if parts[0] != "experiments" || len(parts) > 3 {
+		writeError(w, http.StatusNotFound, "not found")
+		return
+	}
+
+	if len(parts) == 1 {
+		switch r.Method {
+		case http.MethodGet:
+			s.list(w)
+		case http.MethodPost:
+			s.submit(w, r)
+		default:
+			writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		}
+		return
+	}
+
+	id, err := strconv.Atoi(parts[1])
+	if err != nil {
+		writeError(w, http.StatusNotFound, "not found")
+		return
+	}
+	s.mu.Lock()
+	j, ok := s.jobs[id]
+	s.mu.Unlock()
+	if !ok {
+		writeError(w, http.StatusNotFound, "job not found")
+		return
+	}
+
+	resource := ""
+	if len(parts) == 3 {
+		resource = parts[2]
+	}
+	switch {
+	case resource == "" && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, j.status())
+	case resource == "" && r.Method == http.MethodDelete:
+		j.cancel()
+		<-j.done
+		writeJSON(w, http.StatusOK, j.status())
+	case resource == "result" && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, j.progress())
+	case resource == "coverage" && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, j.progress().Coverage)
+	case resource == "hops.svg" && r.Method == http.MethodGet:
+		writeSVG(w, j.progress(), report.HopsChart)
+	case resource == "coverage.svg" && r.Method == http.MethodGet:
+		writeSVG(w, j.progress(), report.CoverageChart)
+	case resource == "" || resource == "result" || resource == "coverage" ||
+		resource == "hops.svg" || resource == "coverage.svg":
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	default:
+		writeError(w, http.StatusNotFound, "not found")
+	}
+}
+
+// Submit starts new job with the config. ErrTooManyJobs is returned when
+// MaxRunning jobs are running, oldest finished jobs are removed when
+// MaxJobs jobs are kept.
+func (s *Server) Submit(cfg runner.Config) (Status, error) {
+	if err := limit(cfg); err != nil {
+		return Status{}, err
+	}
+	// debug dumps are not available via API
+	cfg.StopOnInfinite = false
+	r, err := runner.New(cfg)
+	if err != nil {
+		return Status{}, err
+	}
+
+	s.mu.Lock()
+	if s.running() >= MaxRunning {
+		s.mu.Unlock()
+		return Status{}, ErrTooManyJobs
+	}
+	s.evict()
+	ctx, cancel := context.WithCancel(s.ctx)
+	s.lastID++
+	j := &job{
+		id:     s.lastID,
+		runner: r,
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+	s.jobs[j.id] = j
+	s.mu.Unlock()
+
+	go func() {
+		j.result = r.Run(ctx)
+		cancel()
+		close(j.done)
+	}()
+	return j.status(), nil
+}
+
+func (s *Server) submit(w http.ResponseWriter, r *http.Request) {
+	var cfg runner.Config
+	body := http.MaxBytesReader(w, r.Body, MaxBody)
+	if err := json.NewDecoder(body).Decode(&cfg); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	st, err := s.Submit(cfg)
+	switch {
+	case err == ErrTooManyJobs:
+		writeError(w, http.StatusServiceUnavailable, err.Error())
+		return
+	case err != nil:
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusCreated, st)
+}
+
+// limit checks the config against server limits.
+func limit(cfg runner.Config) error {
+	switch {
+	case cfg.Size > MaxSize:
+		return ErrSizeLimit
+	case cfg.Experiments > MaxExperiments:
+		return ErrExperimentsLimit
+	case cfg.Workers > MaxWorkers:
+		return ErrWorkersLimit
+	case cfg.MaxEpochs > MaxEpochs:
+		return ErrEpochsLimit
+	}
+	return nil
+}
+
+// running returns number of running jobs. s.mu must be held.
+func (s *Server) running() int {
+	n := 0
+	for _, j := range s.jobs {
+		select {
+		case <-j.done:
+		default:
+			n++
+		}
+	}
+	return n
+}
+
+// evict removes the oldest finished jobs until there is room for new one.
+// There is room when less than MaxRunning jobs run. s.mu must be held.
+func (s *Server) evict() {
+	if len(s.jobs) < MaxJobs {
+		return
+	}
+	ids := make([]int, 0, len(s.jobs))
+	for id := range s.jobs {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	for _, id := range ids {
+		if len(s.jobs) < MaxJobs {
+			break
+		}
+		select {
+		case <-s.jobs[id].done:
+			delete(s.jobs, id)
+		default:
+		}
+	}
+}
+
+func (s *Server) list(w http.ResponseWriter) {
+	s.mu.Lock()
+	ids := make([]int, 0, len(s.jobs))
+	for id := range s.jobs {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	jobs := make([]*job, 0, len(ids))
+	for _, id := range ids {
+		jobs = append(jobs, s.jobs[id])
+	}
+	s.mu.Unlock()
+
+	result := make([]Status, 0, len(jobs))
+	for _, j := range jobs {
+		result = append(result, j.status())
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// progress returns final result of finished job or current progress.
+func (j *job) progress() runner.Result {
+	select {
+	case <-j.done:
+		return j.result
+	default:
+		return j.runner.Progress()
+	}
+}
+
+func (j *job) status() Status {
+	st := Status{
+		ID:     j.id,
+		State:  StateRunning,
+		Config: j.runner.Config(),
+		Total:  j.runner.Config().Experiments,
+	}
+	select {
+	case <-j.done:
+		st.Completed = j.result.Completed
+		st.State = StateDone
+		if j.result.Canceled {
+			st.State = StateCanceled
+		}
+	default:
+		st.Completed = j.runner.Progress().Completed
+	}
+	return st
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	buf := new(bytes.Buffer)
+	if err := json.NewEncoder(buf).Encode(v); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	buf.WriteTo(w)
+}
+
+func writeSVG(w http.ResponseWriter, res runner.Result, draw func(io.Writer, runner.Result) error) {
+	buf := new(bytes.Buffer)
+	if err := draw(buf, res); err != nil {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "image/svg+xml")
+	w.Header().Set("Cache-Control", "no-store")
+	buf.WriteTo(w)
+}
+
+func writeError(w http.ResponseWriter, code int, msg string) {
+	writeJSON(w, code, errorResponse{Error: msg})
+}
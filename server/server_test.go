@@ -0,0 +1,128 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gossipmodel/runner"
+
+	"github.com/stretchr/testify/require"
+)
+
+func do(t *testing.T, s *Server, method, path string, body []byte, v interface{}) int {
+	req := httptest.NewRequest(method, path, bytes.NewReader(body))
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, req)
+	if v != nil {
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(v))
+	}
+	return rec.Code
+}
+
+func TestServer(t *testing.T) {
+	s := New(context.Background())
+
+	var st Status
+	code := do(t, s, http.MethodPost, "/experiments", []byte(`{"size":10,"fanout":9,"experiments":5}`), &st)
+	require.Equal(t, http.StatusCreated, code)
+	require.Equal(t, 1, st.ID)
+
+	s.jobs[1].cancel()
+	<-s.jobs[1].done
+
+	var res runner.Result
+	require.Equal(t, http.StatusOK, do(t, s, http.MethodGet, "/experiments/1/result", nil, &res))
+	require.Equal(t, 10, res.Config.Size)
+
+	var list []Status
+	require.Equal(t, http.StatusOK, do(t, s, http.MethodGet, "/experiments", nil, &list))
+	require.Len(t, list, 1)
+	require.NotEqual(t, StateRunning, list[0].State)
+
+	var e errorResponse
+	require.Equal(t, http.StatusBadRequest, do(t, s, http.MethodPost, "/experiments", []byte(`{"size":10}`), &e))
+	require.Equal(t, runner.ErrInvalidFanout.Error(), e.Error)
+
+	require.Equal(t, http.StatusNotFound, do(t, s, http.MethodGet, "/experiments/2", nil, &e))
+	require.Equal(t, http.StatusMethodNotAllowed, do(t, s, http.MethodPut, "/experiments/1", nil, &e))
+}
+
+func TestServer_UI(t *testing.T) {
+	s := New(context.Background())
+
+	var names []string
+	require.Equal(t, http.StatusOK, do(t, s, http.MethodGet, "/algorithms", nil, &names))
+	require.Contains(t, names, "naive-once")
+
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Contains(t, rec.Body.String(), "<html>")
+	require.Contains(t, rec.Body.String(), `id="crash"`)
+	require.Contains(t, rec.Body.String(), `crash: +$('crash').value`)
+
+	var st Status
+	require.Equal(t, http.StatusCreated, do(t, s, http.MethodPost, "/experiments", []byte(`{"size":10,"fanout":9,"experiments":5,"crash":3}`), &st))
+	require.Equal(t, 3, st.Config.Crash)
+	<-s.jobs[1].done
+
+	rec = httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/experiments/1/hops.svg", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "image/svg+xml", rec.Header().Get("Content-Type"))
+}
+
+func TestServer_Limits(t *testing.T) {
+	s := New(context.Background())
+
+	var e errorResponse
+	require.Equal(t, http.StatusBadRequest, do(t, s, http.MethodPost, "/experiments", []byte(`{"size":10,"fanout":9,"experiments":1000000000}`), &e))
+	require.Equal(t, ErrExperimentsLimit.Error(), e.Error)
+	_, err := s.Submit(runner.Config{Size: MaxSize + 1, Fanout: 9, Experiments: 1})
+	require.Equal(t, ErrSizeLimit, err)
+	_, err = s.Submit(runner.Config{Size: 10, Fanout: 9, Experiments: 1, Workers: MaxWorkers + 1})
+	require.Equal(t, ErrWorkersLimit, err)
+	require.Empty(t, s.jobs)
+
+	require.Equal(t, http.StatusBadRequest, do(t, s, http.MethodPost, "/experiments", append(bytes.Repeat([]byte(" "), MaxBody), `{"size":10,"fanout":9,"experiments":1}`...), &e))
+	require.Contains(t, e.Error, "too large")
+	require.Empty(t, s.jobs)
+
+	for id := 1; id <= MaxJobs; id++ {
+		s.jobs[id] = &job{id: id, done: make(chan struct{})}
+		if id > MaxRunning {
+			close(s.jobs[id].done)
+		}
+	}
+	s.lastID = MaxJobs
+	require.Equal(t, http.StatusServiceUnavailable, do(t, s, http.MethodPost, "/experiments", []byte(`{"size":10,"fanout":9,"experiments":1}`), &e))
+	require.Equal(t, ErrTooManyJobs.Error(), e.Error)
+
+	close(s.jobs[2].done)
+	st, err := s.Submit(runner.Config{Size: 10, Fanout: 9, Experiments: 1})
+	require.NoError(t, err)
+	require.Equal(t, MaxJobs+1, st.ID)
+	require.Len(t, s.jobs, MaxJobs)
+	require.NotContains(t, s.jobs, 2)
+	require.Contains(t, s.jobs, 1)
+	require.Contains(t, s.jobs, MaxRunning+1)
+}
+
+// TestServer_Concurrent runs jobs with crypto random sources at the same
+// time, run it with -race.
+func TestServer_Concurrent(t *testing.T) {
+	s := New(context.Background())
+
+	for i := 0; i < 2; i++ {
+		_, err := s.Submit(runner.Config{Size: 100, Fanout: 4, Experiments: 50, Workers: 2})
+		require.NoError(t, err)
+	}
+	for id := 1; id <= 2; id++ {
+		<-s.jobs[id].done
+		require.Equal(t, 50, s.jobs[id].result.Completed)
+	}
+}
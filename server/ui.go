@@ -0,0 +1,148 @@
+package server
+
+// indexHTML is a single page web UI served by the binary. It uses only
+// HTTP API of the server, charts are rendered by the server in SVG.
+const indexHTML = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Push-gossip model</title>
+<style>
+	body { font-family: sans-serif; margin: 20px; color: #222; }
+	.controls { display: grid; grid-template-columns: 140px 320px 80px; gap: 8px 12px; align-items: center; }
+	.charts { display: flex; flex-wrap: wrap; gap: 12px; margin-top: 16px; }
+	.charts img { border: 1px solid #ddd; max-width: 100%; }
+	button { padding: 6px 16px; margin-right: 8px; }
+	#status { margin-top: 12px; font-family: monospace; white-space: pre; }
+</style>
+</head>
+<body>
+<h2>Push-gossip model</h2>
+<div class="controls">
+	<label for="size">Network size</label>
+	<input id="size" type="range" min="2" max="5000" value="100">
+	<output id="size-out"></output>
+
+	<label for="fanout">Fan-out size</label>
+	<input id="fanout" type="range" min="1" max="99" value="10">
+	<output id="fanout-out"></output>
+
+	<label for="experiments">Experiments</label>
+	<input id="experiments" type="range" min="1" max="10000" value="1000">
+	<output id="experiments-out"></output>
+
+	<label for="crash">Crashed nodes</label>
+	<input id="crash" type="range" min="0" max="98" value="0">
+	<output id="crash-out"></output>
+
+	<label for="algorithm">Algorithm</label>
+	<select id="algorithm"></select>
+	<span></span>
+</div>
+<p>
+	<button id="run">Run</button>
+	<button id="cancel" disabled>Cancel</button>
+</p>
+<div id="status"></div>
+<div class="charts">
+	<img id="hops" alt="">
+	<img id="coverage" alt="">
+</div>
+<script>
+(function () {
+	var $ = function (id) { return document.getElementById(id); };
+	var job = null, timer = null;
+
+	function bind(id) {
+		var update = function () {
+			if (id === 'size') {
+				$('fanout').max = Math.max(1, $('size').value - 1);
+				$('crash').max = Math.max(0, $('size').value - 2);
+			}
+			$(id + '-out').textContent = $(id).value;
+		};
+		$(id).addEventListener('input', function () {
+			update();
+			if (id === 'size') {
+				$('fanout-out').textContent = $('fanout').value;
+				$('crash-out').textContent = $('crash').value;
+			}
+		});
+		update();
+	}
+	['size', 'fanout', 'experiments', 'crash'].forEach(bind);
+
+	function request(method, url, body) {
+		return fetch(url, { method: method, body: body ? JSON.stringify(body) : undefined })
+			.then(function (r) {
+				return r.json().then(function (v) {
+					if (!r.ok) { throw new Error(v.error); }
+					return v;
+				});
+			});
+	}
+
+	function show(st) {
+		$('status').textContent = 'Job #' + st.id + ': ' + st.state + ', ' +
+			st.completed + '/' + st.total + ' experiments';
+		var t = Date.now();
+		if (st.completed > 0) {
+			$('hops').src = '/experiments/' + st.id + '/hops.svg?t=' + t;
+			$('coverage').src = '/experiments/' + st.id + '/coverage.svg?t=' + t;
+		}
+		if (st.state !== 'running') {
+			clearInterval(timer);
+			job = null;
+			$('run').disabled = false;
+			$('cancel').disabled = true;
+		}
+	}
+
+	function poll() {
+		if (job === null) { return; }
+		request('GET', '/experiments/' + job).then(show).catch(fail);
+	}
+
+	function fail(err) {
+		$('status').textContent = 'Error: ' + err.message;
+		clearInterval(timer);
+		job = null;
+		$('run').disabled = false;
+		$('cancel').disabled = true;
+	}
+
+	request('GET', '/algorithms').then(function (names) {
+		names.forEach(function (name) {
+			var opt = document.createElement('option');
+			opt.value = opt.textContent = name;
+			$('algorithm').appendChild(opt);
+		});
+		$('algorithm').value = 'naive-once';
+	}).catch(fail);
+
+	$('run').addEventListener('click', function () {
+		$('run').disabled = true;
+		request('POST', '/experiments', {
+			size: +$('size').value,
+			fanout: +$('fanout').value,
+			experiments: +$('experiments').value,
+			crash: +$('crash').value,
+			algorithm: $('algorithm').value
+		}).then(function (st) {
+			job = st.id;
+			$('cancel').disabled = false;
+			show(st);
+			timer = setInterval(poll, 300);
+		}).catch(fail);
+	});
+
+	$('cancel').addEventListener('click', function () {
+		if (job !== null) {
+			request('DELETE', '/experiments/' + job).then(show).catch(fail);
+		}
+	});
+})();
+</script>
+</body>
+</html>
+`
@@ -0,0 +1,249 @@
+package stats
+
+/*
+	Statistical helpers for experiment results: descriptive statistics
+	and significance tests of paired samples.
+*/
+
+import (
+	"errors"
+	"math"
+	"sort"
+)
+
+type (
+	// TTest is a result of paired Student's t-test.
+	TTest struct {
+		N        int     `json:"n"`         // number of pairs
+		MeanDiff float64 `json:"mean_diff"` // mean of a-b differences
+		StdErr   float64 `json:"std_err"`   // standard error of the mean difference
+		T        float64 `json:"t"`         // t statistic
+		P        float64 `json:"p"`         // two-sided p-value
+	}
+
+	// McNemar is a result of exact McNemar test of paired binary outcomes.
+	McNemar struct {
+		OnlyA int     `json:"only_a"` // pairs where only a succeeded
+		OnlyB int     `json:"only_b"` // pairs where only b succeeded
+		P     float64 `json:"p"`      // two-sided p-value
+	}
+
+	// Distribution describes sample of non-negative integer values.
+	Distribution struct {
+		Count int     `json:"count"`
+		Mean  float64 `json:"mean"`
+		Max   int     `json:"max"`
+		Gini  float64 `json:"gini"` // Gini coefficient, 0 for equal values
+		P50   int     `json:"p50"`
+		P90   int     `json:"p90"`
+		P99   int     `json:"p99"`
+	}
+)
+
+var (
+	ErrNotEnoughData = errors.New("not enough data")
+)
+
+// Mean returns arithmetic mean of the sample.
+func Mean(x []float64) float64 {
+	if len(x) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, v := range x {
+		sum += v
+	}
+	return sum / float64(len(x))
+}
+
+// Variance returns unbiased sample variance.
+func Variance(x []float64) float64 {
+	if len(x) < 2 {
+		return 0
+	}
+	m := Mean(x)
+	sum := 0.0
+	for _, v := range x {
+		sum += (v - m) * (v - m)
+	}
+	return sum / float64(len(x)-1)
+}
+
+// StdDev returns unbiased sample standard deviation.
+func StdDev(x []float64) float64 {
+	return math.Sqrt(Variance(x))
+}
+
+// PairedTTest tests hypothesis that mean of a-b differences is zero.
+func PairedTTest(a, b []float64) (TTest, error) {
+	if len(a) != len(b) || len(a) < 2 {
+		return TTest{}, ErrNotEnoughData
+	}
+	diff := make([]float64, len(a))
+	for i := range a {
+		diff[i] = a[i] - b[i]
+	}
+	res := TTest{
+		N:        len(diff),
+		MeanDiff: Mean(diff),
+		StdErr:   StdDev(diff) / math.Sqrt(float64(len(diff))),
+	}
+	switch {
+	case res.StdErr > 0:
+		res.T = res.MeanDiff / res.StdErr
+		res.P = 2 * StudentTSurvival(math.Abs(res.T), float64(res.N-1))
+	case res.MeanDiff == 0:
+		res.P = 1
+	default:
+		res.T = math.Copysign(math.Inf(1), res.MeanDiff)
+	}
+	return res, nil
+}
+
+// McNemarTest tests hypothesis that both variants succeed equally often,
+// a[i] and b[i] are outcomes of i-th pair.
+func McNemarTest(a, b []bool) (McNemar, error) {
+	if len(a) != len(b) {
+		return McNemar{}, ErrNotEnoughData
+	}
+	var res McNemar
+	for i := range a {
+		switch {
+		case a[i] && !b[i]:
+			res.OnlyA++
+		case !a[i] && b[i]:
+			res.OnlyB++
+		}
+	}
+	n := res.OnlyA + res.OnlyB
+	k := res.OnlyA
+	if res.OnlyB < k {
+		k = res.OnlyB
+	}
+	res.P = math.Min(1, 2*BinomialCDF(k, n, 0.5))
+	return res, nil
+}
+
+// StudentTSurvival returns P(T > t) for Student's t distribution with
+// df degrees of freedom, t >= 0.
+func StudentTSurvival(t, df float64) float64 {
+	x := df / (df + t*t)
+	return 0.5 * RegIncBeta(df/2, 0.5, x)
+}
+
+// BinomialCDF returns P(X <= k) for binomial distribution.
+func BinomialCDF(k, n int, p float64) float64 {
+	if k >= n {
+		return 1
+	}
+	if k < 0 {
+		return 0
+	}
+	sum := 0.0
+	for i := 0; i <= k; i++ {
+		sum += math.Exp(logChoose(n, i) + float64(i)*math.Log(p) + float64(n-i)*math.Log1p(-p))
+	}
+	return math.Min(1, sum)
+}
+
+func logChoose(n, k int) float64 {
+	a, _ := math.Lgamma(float64(n + 1))
+	b, _ := math.Lgamma(float64(k + 1))
+	c, _ := math.Lgamma(float64(n - k + 1))
+	return a - b - c
+}
+
+// RegIncBeta returns regularized incomplete beta function I_x(a, b).
+func RegIncBeta(a, b, x float64) float64 {
+	switch {
+	case x <= 0:
+		return 0
+	case x >= 1:
+		return 1
+	}
+	la, _ := math.Lgamma(a)
+	lb, _ := math.Lgamma(b)
+	lab, _ := math.Lgamma(a + b)
+	front := math.Exp(lab - la - lb + a*math.Log(x) + b*math.Log1p(-x))
+	if x < (a+1)/(a+b+2) {
+		return front * betaFraction(a, b, x) / a
+	}
+	return 1 - front*betaFraction(b, a, 1-x)/b
+}
+
+// betaFraction evaluates continued fraction of incomplete beta function
+// with modified Lentz's method.
+func betaFraction(a, b, x float64) float64 {
+	const (
+		maxIter = 300
+		eps     = 1e-14
+		tiny    = 1e-300
+	)
+	c, d := 1.0, 1-(a+b)*x/(a+1)
+	if math.Abs(d) < tiny {
+		d = tiny
+	}
+	d = 1 / d
+	h := d
+	for m := 1; m <= maxIter; m++ {
+		fm := float64(m)
+		num := fm * (b - fm) * x / ((a + 2*fm - 1) * (a + 2*fm))
+		for i := 0; i < 2; i++ {
+			d = 1 + num*d
+			if math.Abs(d) < tiny {
+				d = tiny
+			}
+			c = 1 + num/c
+			if math.Abs(c) < tiny {
+				c = tiny
+			}
+			d = 1 / d
+			h *= d * c
+			num = -(a + fm) * (a + b + fm) * x / ((a + 2*fm) * (a + 2*fm + 1))
+		}
+		if math.Abs(d*c-1) < eps {
+			break
+		}
+	}
+	return h
+}
+
+// Summary returns distribution of non-negative integer values given as
+// histogram: number of values by value.
+func Summary(hist map[int]int) Distribution {
+	values := make([]int, 0, len(hist))
+	var d Distribution
+	sum := 0.0
+	for v, c := range hist {
+		values = append(values, v)
+		d.Count += c
+		sum += float64(v) * float64(c)
+	}
+	if d.Count == 0 {
+		return d
+	}
+	sort.Ints(values)
+	d.Mean = sum / float64(d.Count)
+	d.Max = values[len(values)-1]
+
+	// nearest rank percentiles
+	ranks := []*int{&d.P50, &d.P90, &d.P99}
+	targets := []float64{0.5, 0.9, 0.99}
+	// Gini coefficient: 2*sum(i*x_i)/(n*sum(x)) - (n+1)/n over sorted x
+	rank, weighted := 0, 0.0
+	next := 0
+	for _, v := range values {
+		c := hist[v]
+		weighted += float64(v) * (float64(c)*float64(rank) + float64(c)*float64(c+1)/2)
+		rank += c
+		for next < len(targets) && rank >= int(math.Ceil(targets[next]*float64(d.Count))) {
+			*ranks[next] = v
+			next++
+		}
+	}
+	if sum > 0 {
+		n := float64(d.Count)
+		d.Gini = 2*weighted/(n*sum) - (n+1)/n
+	}
+	return d
+}
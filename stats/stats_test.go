@@ -0,0 +1,78 @@
+package stats
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStudentTSurvival(t *testing.T) {
+	require.InDelta(t, 0.5, StudentTSurvival(0, 5), 1e-9)
+	require.InDelta(t, 0.036694, StudentTSurvival(2, 10), 1e-6)
+	require.InDelta(t, 0.0011276, StudentTSurvival(3.5, 20), 1e-6)
+}
+
+func TestPairedTTest(t *testing.T) {
+	a := []float64{3, 4, 3, 5, 4, 3}
+	b := []float64{4, 4, 4, 5, 5, 4}
+	res, err := PairedTTest(a, b)
+	require.NoError(t, err)
+	require.Equal(t, 6, res.N)
+	require.InDelta(t, -2.0/3, res.MeanDiff, 1e-9)
+	require.InDelta(t, -3.162278, res.T, 1e-6)
+	require.InDelta(t, 0.025, res.P, 1e-3)
+
+	res, err = PairedTTest(a, a)
+	require.NoError(t, err)
+	require.Equal(t, 1.0, res.P)
+
+	_, err = PairedTTest(a, b[:3])
+	require.Equal(t, ErrNotEnoughData, err)
+}
+
+func TestMcNemarTest(t *testing.T) {
+	a := []bool{true, true, true, true, true, true, false}
+	b := []bool{false, false, false, false, false, false, true}
+	res, err := McNemarTest(a, b)
+	require.NoError(t, err)
+	require.Equal(t, 6, res.OnlyA)
+	require.Equal(t, 1, res.OnlyB)
+	require.InDelta(t, 0.125, res.P, 1e-9)
+
+	res, err = McNemarTest(a, a)
+	require.NoError(t, err)
+	require.Equal(t, 1.0, res.P)
+}
+
+func TestMeanVariance(t *testing.T) {
+	x := []float64{2, 4, 4, 4, 5, 5, 7, 9}
+	require.Equal(t, 5.0, Mean(x))
+	require.InDelta(t, 4.571429, Variance(x), 1e-6)
+	require.Equal(t, 0.0, Variance(x[:1]))
+}
+
+func TestSummary(t *testing.T) {
+	require.Equal(t, Distribution{}, Summary(nil))
+
+	d := Summary(map[int]int{1: 4})
+	require.Equal(t, Distribution{Count: 4, Mean: 1, Max: 1, P50: 1, P90: 1, P99: 1}, d)
+
+	// values 0, 0, 0, 4: single node does everything
+	d = Summary(map[int]int{0: 3, 4: 1})
+	require.Equal(t, 4, d.Max)
+	require.Equal(t, 0, d.P50)
+	require.Equal(t, 4, d.P90)
+	require.InDelta(t, 0.75, d.Gini, 1e-12)
+
+	// values 1..10
+	hist := make(map[int]int)
+	for v := 1; v <= 10; v++ {
+		hist[v] = 1
+	}
+	d = Summary(hist)
+	require.Equal(t, 5.5, d.Mean)
+	require.Equal(t, 5, d.P50)
+	require.Equal(t, 9, d.P90)
+	require.Equal(t, 10, d.P99)
+	require.InDelta(t, 0.3, d.Gini, 1e-12)
+}